@@ -1,8 +1,8 @@
 package acfundanmu
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 
@@ -222,16 +222,16 @@ type LiveCutInfo struct {
 }
 
 // 获取直播间排名前 50 的在线观众信息列表
-func (t *token) getWatchingList(liveID string) (watchingList []WatchingUser, e error) {
+func (t *token) getWatchingList(ctx context.Context, liveID string) (watchingList []WatchingUser, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getWatchingList() error: %v", err)
+			e = fmt.Errorf("getWatchingList() error: %w", recoverErr(err))
 		}
 	}()
 
 	form := t.defaultForm(liveID)
 	defer fasthttp.ReleaseArgs(form)
-	body, err := t.fetchKuaiShouAPI(watchingListURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, watchingListURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -239,7 +239,7 @@ func (t *token) getWatchingList(liveID string) (watchingList []WatchingUser, e e
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取在线观众列表失败，响应为 %s", string(body)))
+		panic(newAPIError("获取在线观众列表失败", v, body))
 	}
 
 	watchArray := v.GetArray("data", "list")
@@ -263,7 +263,7 @@ func (t *token) getWatchingList(liveID string) (watchingList []WatchingUser, e e
 			case "managerType":
 				watchingList[i].ManagerType = ManagerType(v.GetInt())
 			default:
-				log.Printf("在线观众列表里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+				t.logger().Warn("在线观众列表里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 			}
 		})
 	}
@@ -272,17 +272,17 @@ func (t *token) getWatchingList(liveID string) (watchingList []WatchingUser, e e
 }
 
 // 获取主播最近七日内的礼物贡献榜前 50 名观众的详细信息
-func (t *token) getBillboard(uid int64) (billboard []BillboardUser, e error) {
+func (t *token) getBillboard(ctx context.Context, uid int64) (billboard []BillboardUser, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getBillboard() error: %v", err)
+			e = fmt.Errorf("getBillboard() error: %w", recoverErr(err))
 		}
 	}()
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("authorId", strconv.FormatInt(uid, 10))
-	body, err := t.fetchKuaiShouAPI(billboardURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, billboardURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -290,7 +290,7 @@ func (t *token) getBillboard(uid int64) (billboard []BillboardUser, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取主播最近七日内的礼物贡献榜失败，响应为 %s", string(body)))
+		panic(newAPIError("获取主播最近七日内的礼物贡献榜失败", v, body))
 	}
 
 	billboardArray := v.GetArray("data", "list")
@@ -310,7 +310,7 @@ func (t *token) getBillboard(uid int64) (billboard []BillboardUser, e error) {
 			case "customData":
 				billboard[i].CustomData = string(v.GetStringBytes())
 			default:
-				log.Printf("礼物贡献榜里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+				t.logger().Warn("礼物贡献榜里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 			}
 		})
 	}
@@ -319,16 +319,16 @@ func (t *token) getBillboard(uid int64) (billboard []BillboardUser, e error) {
 }
 
 // 获取直播总结信息
-func (t *token) getSummary(liveID string) (summary *Summary, e error) {
+func (t *token) getSummary(ctx context.Context, liveID string) (summary *Summary, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getSummary() error: %v", err)
+			e = fmt.Errorf("getSummary() error: %w", recoverErr(err))
 		}
 	}()
 
 	form := t.defaultForm(liveID)
 	defer fasthttp.ReleaseArgs(form)
-	body, err := t.fetchKuaiShouAPI(endSummaryURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, endSummaryURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -336,7 +336,7 @@ func (t *token) getSummary(liveID string) (summary *Summary, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取直播总结信息失败，响应为 %s", string(body)))
+		panic(newAPIError("获取直播总结信息失败", v, body))
 	}
 
 	summary = new(Summary)
@@ -355,7 +355,7 @@ func (t *token) getSummary(liveID string) (summary *Summary, e error) {
 			summary.DiamondCount = v.GetInt("1")
 			summary.BananaCount = v.GetInt("2")
 		default:
-			log.Printf("直播总结信息里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			t.logger().Warn("直播总结信息里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -363,15 +363,15 @@ func (t *token) getSummary(liveID string) (summary *Summary, e error) {
 }
 
 // 获取抢到红包的用户列表
-func (t *token) getLuckList(liveID, redpackID, redpackBizUnit string) (luckyList []LuckyUser, e error) {
+func (t *token) getLuckList(ctx context.Context, liveID, redpackID, redpackBizUnit string) (luckyList []LuckyUser, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getLuckList() error: %v", err)
+			e = fmt.Errorf("getLuckList() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取抢到红包的用户列表需要登陆 AcFun 帐号"))
+		panic(errNeedLogin("获取抢到红包的用户列表需要登陆 AcFun 帐号"))
 	}
 
 	form := t.defaultForm(liveID)
@@ -382,7 +382,7 @@ func (t *token) getLuckList(liveID, redpackID, redpackBizUnit string) (luckyList
 		form.Set("redpackBizUnit", redpackBizUnit)
 	}
 	form.Set("redpackId", redpackID)
-	body, err := t.fetchKuaiShouAPI(redpackLuckListURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, redpackLuckListURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -390,7 +390,7 @@ func (t *token) getLuckList(liveID, redpackID, redpackBizUnit string) (luckyList
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取抢到红包的用户列表失败，响应为 %s", string(body)))
+		panic(newAPIError("获取抢到红包的用户列表失败", v, body))
 	}
 
 	luckyArray := v.GetArray("data", "luckyList")
@@ -410,13 +410,13 @@ func (t *token) getLuckList(liveID, redpackID, redpackBizUnit string) (luckyList
 					case "headPic":
 						luckyList[i].Avatar = string(v.GetStringBytes("0", "url"))
 					default:
-						log.Printf("抢到红包的用户列表里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+						t.logger().Warn("抢到红包的用户列表里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 					}
 				})
 			case "grabAmount":
 				luckyList[i].GrabAmount = v.GetInt()
 			default:
-				log.Printf("抢到红包的用户列表里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+				t.logger().Warn("抢到红包的用户列表里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 			}
 		})
 	}
@@ -425,17 +425,17 @@ func (t *token) getLuckList(liveID, redpackID, redpackBizUnit string) (luckyList
 }
 
 // 获取直播回放的相关信息
-func (t *token) getPlayback(liveID string) (playback *Playback, e error) {
+func (t *token) getPlayback(ctx context.Context, liveID string) (playback *Playback, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getPlayback() error: %v", err)
+			e = fmt.Errorf("getPlayback() error: %w", recoverErr(err))
 		}
 	}()
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("liveId", liveID)
-	body, err := t.fetchKuaiShouAPI(playbackURL, form, true)
+	body, err := t.fetchKuaiShouAPI(ctx, playbackURL, form, true, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -443,18 +443,18 @@ func (t *token) getPlayback(liveID string) (playback *Playback, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取直播回放的相关信息失败，响应为 %s", string(body)))
+		panic(newAPIError("获取直播回放的相关信息失败", v, body))
 	}
 	adaptiveManifest := v.GetStringBytes("data", "adaptiveManifest")
 	v, err = p.ParseBytes(adaptiveManifest)
 	checkErr(err)
 	if len(v.GetArray("adaptationSet")) > 1 {
-		log.Println("adaptationSet 列表长度大于 1，请报告 issue")
+		t.logger().Warn("adaptationSet 列表长度大于 1，请报告 issue")
 	}
 	v = v.Get("adaptationSet", "0")
 	duration := v.GetInt64("duration")
 	if len(v.GetArray("representation")) > 1 {
-		log.Println("representation 列表长度大于 1，请报告 issue")
+		t.logger().Warn("representation 列表长度大于 1，请报告 issue")
 	}
 	v = v.Get("representation", "0")
 	playback = &Playback{
@@ -466,7 +466,7 @@ func (t *token) getPlayback(liveID string) (playback *Playback, e error) {
 		Height:    v.GetInt("height"),
 	}
 	if len(v.GetArray("backupUrl")) > 1 {
-		log.Println("backupUrl 列表长度大于 1，请报告 issue")
+		t.logger().Warn("backupUrl 列表长度大于 1，请报告 issue")
 	}
 
 	return playback, nil
@@ -474,14 +474,14 @@ func (t *token) getPlayback(liveID string) (playback *Playback, e error) {
 
 // 获取直播源信息，和 getLiveToken() 重复了
 /*
-func (t *token) getPlayURL() (e error) {
+func (t *token) getPlayURL(ctx context.Context) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getPlayURL() error: %w", err)
+			e = fmt.Errorf("getPlayURL() error: %w", recoverErr(err))
 		}
 	}()
 
-	body, err := t.fetchKuaiShouAPI(getPlayURL, nil, false)
+	body, err := t.fetchKuaiShouAPI(ctx, getPlayURL, nil, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -489,7 +489,7 @@ func (t *token) getPlayURL() (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取直播源信息失败，响应为 %s", string(body)))
+		panic(newAPIError("获取直播源信息失败", v, body))
 	}
 
 	//videoPlayRes := string(v.GetStringBytes("data", "videoPlayRes"))
@@ -499,17 +499,17 @@ func (t *token) getPlayURL() (e error) {
 */
 
 // 获取全部礼物的数据
-func (t *token) getAllGift() (gifts map[int64]GiftDetail, e error) {
+func (t *token) getAllGift(ctx context.Context) (gifts map[int64]GiftDetail, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getAllGift() error: %v", err)
+			e = fmt.Errorf("getAllGift() error: %w", recoverErr(err))
 		}
 	}()
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("visitorId", strconv.FormatInt(t.UserID, 10))
-	body, err := t.fetchKuaiShouAPI(allGiftURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, allGiftURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -517,28 +517,28 @@ func (t *token) getAllGift() (gifts map[int64]GiftDetail, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取全部礼物的数据失败，响应为 %s", string(body)))
+		panic(newAPIError("获取全部礼物的数据失败", v, body))
 	}
 
 	return updateGiftList(v), nil
 }
 
 // 获取钱包里 AC 币和拥有的香蕉的数量
-func (t *token) getWalletBalance() (accoins int, bananas int, e error) {
+func (t *token) getWalletBalance(ctx context.Context) (accoins int, bananas int, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getWalletBalance() error: %v", err)
+			e = fmt.Errorf("getWalletBalance() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取钱包里 AC 币和拥有的香蕉的数量需要登陆 AcFun 帐号"))
+		panic(errNeedLogin("获取钱包里 AC 币和拥有的香蕉的数量需要登陆 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("visitorId", strconv.FormatInt(t.UserID, 10))
-	body, err := t.fetchKuaiShouAPI(walletBalanceURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, walletBalanceURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -546,7 +546,7 @@ func (t *token) getWalletBalance() (accoins int, bananas int, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取拥有的香蕉和钱包里AC币的数量失败，响应为 %s", string(body)))
+		panic(newAPIError("获取拥有的香蕉和钱包里AC币的数量失败", v, body))
 	}
 
 	o := v.GetObject("data", "payWalletTypeToBalance")
@@ -557,7 +557,7 @@ func (t *token) getWalletBalance() (accoins int, bananas int, e error) {
 		case "2":
 			bananas = v.GetInt()
 		default:
-			log.Printf("用户钱包里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			t.logger().Warn("用户钱包里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -565,22 +565,22 @@ func (t *token) getWalletBalance() (accoins int, bananas int, e error) {
 }
 
 // 获取主播踢人的历史记录
-func (t *token) getKickHistory(liveID string, count, page int) (list []KickHistory, lastPage bool, e error) {
+func (t *token) getKickHistory(ctx context.Context, liveID string, count, page int) (list []KickHistory, lastPage bool, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getKickHistory() error: %v", err)
+			e = fmt.Errorf("getKickHistory() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取主播踢人的历史记录需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("获取主播踢人的历史记录需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := t.defaultForm(liveID)
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("limit", strconv.Itoa(count))
 	form.Set("pcursor", strconv.Itoa(page))
-	body, err := t.fetchKuaiShouAPI(kickHistoryURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, kickHistoryURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -588,7 +588,7 @@ func (t *token) getKickHistory(liveID string, count, page int) (list []KickHisto
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取主播踢人的历史记录失败，响应为 %s", string(body)))
+		panic(newAPIError("获取主播踢人的历史记录失败", v, body))
 	}
 
 	v = v.Get("data")
@@ -605,7 +605,7 @@ func (t *token) getKickHistory(liveID string, count, page int) (list []KickHisto
 			case "kickTime":
 				list[i].KickTime = v.GetInt64()
 			default:
-				log.Printf("主播踢人的历史记录里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+				t.logger().Warn("主播踢人的历史记录里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 			}
 		})
 	}
@@ -618,21 +618,21 @@ func (t *token) getKickHistory(liveID string, count, page int) (list []KickHisto
 }
 
 // 获取主播的房管列表
-func (t *token) getManagerList() (managerList []Manager, e error) {
+func (t *token) getManagerList(ctx context.Context) (managerList []Manager, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getManagerList() error: %v", err)
+			e = fmt.Errorf("getManagerList() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取主播的房管列表需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("获取主播的房管列表需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("visitorId", strconv.FormatInt(t.UserID, 10))
-	body, err := t.fetchKuaiShouAPI(managerListURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, managerListURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -640,7 +640,7 @@ func (t *token) getManagerList() (managerList []Manager, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取主播的房管列表失败，响应为 %s", string(body)))
+		panic(newAPIError("获取主播的房管列表失败", v, body))
 	}
 
 	list := v.GetArray("data", "list")
@@ -660,7 +660,7 @@ func (t *token) getManagerList() (managerList []Manager, e error) {
 			case "online":
 				managerList[i].Online = v.GetBool()
 			default:
-				log.Printf("主播的房管列表里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+				t.logger().Warn("主播的房管列表里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 			}
 		})
 	}
@@ -693,7 +693,7 @@ func getMedalJSON(v *fastjson.Value, uid int64) *Medal {
 		case "currentDegreeLimit":
 			medal.CurrentDegreeLimit = v.GetInt()
 		default:
-			log.Printf("守护徽章信息里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			defaultLogger().Warn("守护徽章信息里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 	medal.UserID = uid
@@ -726,7 +726,7 @@ func getMedalDegreeJSON(v *fastjson.Value) *MedalDegree {
 		case "bananaDegreeLimit":
 			medal.BananaDegreeLimit = v.GetInt()
 		default:
-			log.Printf("守护徽章亲密度信息里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			defaultLogger().Warn("守护徽章亲密度信息里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -734,22 +734,25 @@ func getMedalDegreeJSON(v *fastjson.Value) *MedalDegree {
 }
 
 // 获取登陆帐号拥有的指定主播的守护徽章详细信息
-func (t *token) getMedalDetail(uid int64) (medal *MedalDetail, e error) {
+func (t *token) getMedalDetail(ctx context.Context, uid int64) (medal *MedalDetail, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getMedalDetail() error: %v", err)
+			e = fmt.Errorf("getMedalDetail() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取登陆帐号拥有的指定主播的守护徽章详细信息需要登陆 AcFun 帐号"))
+		panic(errNeedLogin("获取登陆帐号拥有的指定主播的守护徽章详细信息需要登陆 AcFun 帐号"))
 	}
 
 	client := &httpClient{
-		url:      fmt.Sprintf(medalDetailURL, uid),
-		method:   "GET",
-		cookies:  t.Cookies,
-		deviceID: t.DeviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       fmt.Sprintf(medalDetailURL, uid),
+		method:    "GET",
+		cookies:   t.Cookies,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -759,7 +762,7 @@ func (t *token) getMedalDetail(uid int64) (medal *MedalDetail, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取登陆帐号拥有的指定主播的守护徽章详细信息失败，响应为 %s", string(body)))
+		panic(newAPIError("获取登陆帐号拥有的指定主播的守护徽章详细信息失败", v, body))
 	}
 
 	medal = new(MedalDetail)
@@ -775,7 +778,7 @@ func (t *token) getMedalDetail(uid int64) (medal *MedalDetail, e error) {
 		case "rankIndex":
 			medal.UserRank = string(v.GetStringBytes())
 		default:
-			log.Printf("守护徽章详细信息里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			t.logger().Warn("守护徽章详细信息里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -783,22 +786,25 @@ func (t *token) getMedalDetail(uid int64) (medal *MedalDetail, e error) {
 }
 
 // 获取登陆帐号拥有的守护徽章列表
-func (t *token) getMedalList() (medalList []Medal, e error) {
+func (t *token) getMedalList(ctx context.Context) (medalList []Medal, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getMedalList() error: %v", err)
+			e = fmt.Errorf("getMedalList() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取登陆帐号拥有的守护徽章列表需要登陆 AcFun 帐号"))
+		panic(errNeedLogin("获取登陆帐号拥有的守护徽章列表需要登陆 AcFun 帐号"))
 	}
 
 	client := &httpClient{
-		url:      medalListURL,
-		method:   "GET",
-		cookies:  t.Cookies,
-		deviceID: t.DeviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       medalListURL,
+		method:    "GET",
+		cookies:   t.Cookies,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -808,7 +814,7 @@ func (t *token) getMedalList() (medalList []Medal, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取登陆帐号拥有的守护徽章列表失败，响应为 %s", string(body)))
+		panic(newAPIError("获取登陆帐号拥有的守护徽章列表失败", v, body))
 	}
 
 	o := v.GetObject()
@@ -826,7 +832,7 @@ func (t *token) getMedalList() (medalList []Medal, e error) {
 				medalList = append(medalList, *medal)
 			}
 		default:
-			log.Printf("登陆帐号拥有的守护徽章列表里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			t.logger().Warn("登陆帐号拥有的守护徽章列表里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -834,24 +840,28 @@ func (t *token) getMedalList() (medalList []Medal, e error) {
 }
 
 // 获取直播统计数据
-func (t *token) getLiveData(days int) (data *LiveData, e error) {
+func (t *token) getLiveData(ctx context.Context, days int) (data *LiveData, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getLiveData() error: %v", err)
+			e = fmt.Errorf("getLiveData() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取直播统计数据需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("获取直播统计数据需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("days", strconv.Itoa(days))
 	client := &httpClient{
+		client:      t.client,
+		endpoints:   t.endpoints,
+		ctx:         ctx,
 		url:         liveDataURL,
 		body:        form.QueryString(),
 		method:      "POST",
+		readOnly:    true,
 		cookies:     t.Cookies,
 		contentType: formContentType,
 		deviceID:    t.DeviceID,
@@ -864,7 +874,7 @@ func (t *token) getLiveData(days int) (data *LiveData, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取直播统计数据失败，响应为 %s", string(body)))
+		panic(newAPIError("获取直播统计数据失败", v, body))
 	}
 
 	data = new(LiveData)
@@ -896,7 +906,7 @@ func (t *token) getLiveData(days int) (data *LiveData, e error) {
 				case "totalBananaCount":
 					data.Overview.BananaCount = v.GetInt()
 				default:
-					log.Printf("直播统计数据里的overview出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+					t.logger().Warn("直播统计数据里的overview出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 				}
 			})
 		case "liveDetail":
@@ -925,7 +935,7 @@ func (t *token) getLiveData(days int) (data *LiveData, e error) {
 						case "bananaCount":
 							d[i].BananaCount = v.GetInt()
 						default:
-							log.Printf("直播统计数据里的liveDetail出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+							t.logger().Warn("直播统计数据里的liveDetail出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 						}
 					})
 				}
@@ -955,12 +965,12 @@ func (t *token) getLiveData(days int) (data *LiveData, e error) {
 					case "totalBananaCount":
 						data.DailyData[i].BananaCount = v.GetInt()
 					default:
-						log.Printf("直播统计数据里的dailyData出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+						t.logger().Warn("直播统计数据里的dailyData出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 					}
 				})
 			}
 		default:
-			log.Printf("直播统计数据里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			t.logger().Warn("直播统计数据里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -968,24 +978,28 @@ func (t *token) getLiveData(days int) (data *LiveData, e error) {
 }
 
 // 获取直播剪辑信息
-func (t *token) getLiveCutInfo(uid int64, liveID string) (info *LiveCutInfo, e error) {
+func (t *token) getLiveCutInfo(ctx context.Context, uid int64, liveID string) (info *LiveCutInfo, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getLiveCutInfo() error: %v", err)
+			e = fmt.Errorf("getLiveCutInfo() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取直播剪辑信息需要登陆 AcFun 帐号"))
+		panic(errNeedLogin("获取直播剪辑信息需要登陆 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set(sid, midground)
 	client := &httpClient{
+		client:      t.client,
+		endpoints:   t.endpoints,
+		ctx:         ctx,
 		url:         getTokenURL,
 		body:        form.QueryString(),
 		method:      "POST",
+		readOnly:    true,
 		cookies:     t.Cookies,
 		contentType: formContentType,
 		referer:     t.livePage,
@@ -999,16 +1013,19 @@ func (t *token) getLiveCutInfo(uid int64, liveID string) (info *LiveCutInfo, e e
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取AcFun token失败，响应为 %s", string(body)))
+		panic(newAPIError("获取AcFun token失败", v, body))
 	}
 
 	token := string(v.GetStringBytes(midgroundAt))
 
 	client = &httpClient{
-		url:      fmt.Sprintf(liveCutInfoURL, uid, liveID),
-		method:   "GET",
-		cookies:  t.Cookies,
-		deviceID: t.DeviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       fmt.Sprintf(liveCutInfoURL, uid, liveID),
+		method:    "GET",
+		cookies:   t.Cookies,
+		deviceID:  t.DeviceID,
 	}
 	body, err = client.request()
 	checkErr(err)
@@ -1016,7 +1033,7 @@ func (t *token) getLiveCutInfo(uid int64, liveID string) (info *LiveCutInfo, e e
 	v, err = p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取直播剪辑信息失败，响应为：%s", string(body)))
+		panic(newAPIError("获取直播剪辑信息失败", v, body))
 	}
 
 	var status bool
@@ -1026,7 +1043,7 @@ func (t *token) getLiveCutInfo(uid int64, liveID string) (info *LiveCutInfo, e e
 	} else if statusNum == 2 {
 		status = false
 	} else {
-		panic(fmt.Errorf("获取直播剪辑信息失败，响应为：%s", string(body)))
+		panic(newAPIError("获取直播剪辑信息失败", v, body))
 	}
 	url := string(v.GetStringBytes("liveCutUrl"))
 	info = &LiveCutInfo{
@@ -1039,17 +1056,20 @@ func (t *token) getLiveCutInfo(uid int64, liveID string) (info *LiveCutInfo, e e
 }
 
 // 获取指定用户正在佩戴的守护徽章信息
-func getUserMedal(uid int64, deviceID string) (medal *Medal, e error) {
+func (t *token) getUserMedal(ctx context.Context, uid int64) (medal *Medal, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getUserMedal() error: %v", err)
+			e = fmt.Errorf("getUserMedal() error: %w", recoverErr(err))
 		}
 	}()
 
 	client := &httpClient{
-		url:      fmt.Sprintf(userMedalURL, uid),
-		method:   "GET",
-		deviceID: deviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       fmt.Sprintf(userMedalURL, uid),
+		method:    "GET",
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -1059,7 +1079,7 @@ func getUserMedal(uid int64, deviceID string) (medal *Medal, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取指定用户正在佩戴的守护徽章信息失败，响应为 %s", string(body)))
+		panic(newAPIError("获取指定用户正在佩戴的守护徽章信息失败", v, body))
 	}
 
 	medal = new(Medal)
@@ -1079,7 +1099,7 @@ func getUserMedal(uid int64, deviceID string) (medal *Medal, e error) {
 			medal.UperAvatar = string(v.GetStringBytes())
 		case "uperHeadImgInfo":
 		default:
-			log.Printf("指定用户正在佩戴的守护徽章信息里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			defaultLogger().Warn("指定用户正在佩戴的守护徽章信息里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 	medal.UserID = uid
@@ -1165,18 +1185,21 @@ func getUserLiveInfoJSON(v *fastjson.Value) *UserLiveInfo {
 }
 
 // 获取指定用户的直播信息
-func getUserLiveInfo(uid int64, cookies Cookies, deviceID string) (info *UserLiveInfo, e error) {
+func (t *token) getUserLiveInfo(ctx context.Context, uid int64) (info *UserLiveInfo, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getUserLiveInfo() error: %v", err)
+			e = fmt.Errorf("getUserLiveInfo() error: %w", recoverErr(err))
 		}
 	}()
 
 	client := &httpClient{
-		url:      fmt.Sprintf(liveInfoURL, uid),
-		method:   "GET",
-		cookies:  cookies,
-		deviceID: deviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       fmt.Sprintf(liveInfoURL, uid),
+		method:    "GET",
+		cookies:   t.Cookies,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -1186,25 +1209,28 @@ func getUserLiveInfo(uid int64, cookies Cookies, deviceID string) (info *UserLiv
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取指定用户的直播信息失败，响应为 %s", string(body)))
+		panic(newAPIError("获取指定用户的直播信息失败", v, body))
 	}
 
 	return getUserLiveInfoJSON(v), nil
 }
 
 // 获取指定用户的信息
-func getUserInfo(uid int64, cookies Cookies, deviceID string) (info *UserProfileInfo, e error) {
+func (t *token) getUserInfo(ctx context.Context, uid int64) (info *UserProfileInfo, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getUserProfile() error: %v", err)
+			e = fmt.Errorf("getUserProfile() error: %w", recoverErr(err))
 		}
 	}()
 
 	client := &httpClient{
-		url:      fmt.Sprintf(userInfoURL, uid),
-		method:   "GET",
-		cookies:  cookies,
-		deviceID: deviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       fmt.Sprintf(userInfoURL, uid),
+		method:    "GET",
+		cookies:   t.Cookies,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -1214,7 +1240,7 @@ func getUserInfo(uid int64, cookies Cookies, deviceID string) (info *UserProfile
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取指定用户的信息失败，响应为 %s", string(body)))
+		panic(newAPIError("获取指定用户的信息失败", v, body))
 	}
 
 	info = new(UserProfileInfo)
@@ -1256,18 +1282,21 @@ func getUserInfo(uid int64, cookies Cookies, deviceID string) (info *UserProfile
 }
 
 // 获取指定主播的守护榜
-func getMedalRankList(uid int64, cookies Cookies, deviceID string) (medalRankList *MedalRankList, e error) {
+func (t *token) getMedalRankList(ctx context.Context, uid int64) (medalRankList *MedalRankList, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getMedalRankList() error: %v", err)
+			e = fmt.Errorf("getMedalRankList() error: %w", recoverErr(err))
 		}
 	}()
 
 	client := &httpClient{
-		url:      fmt.Sprintf(medalRankURL, uid),
-		method:   "GET",
-		cookies:  cookies,
-		deviceID: deviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       fmt.Sprintf(medalRankURL, uid),
+		method:    "GET",
+		cookies:   t.Cookies,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -1277,7 +1306,7 @@ func getMedalRankList(uid int64, cookies Cookies, deviceID string) (medalRankLis
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取指定主播的守护榜失败，响应为 %s", string(body)))
+		panic(newAPIError("获取指定主播的守护榜失败", v, body))
 	}
 
 	medalRankList = new(MedalRankList)
@@ -1303,7 +1332,7 @@ func getMedalRankList(uid int64, cookies Cookies, deviceID string) (medalRankLis
 					case "medalLevel":
 						medalRankList.RankList[i].Level = v.GetInt()
 					default:
-						log.Printf("用户的守护徽章信息里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+						defaultLogger().Warn("用户的守护徽章信息里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 					}
 				})
 				medalRankList.RankList[i].UserID = l.GetInt64("userId")
@@ -1319,7 +1348,7 @@ func getMedalRankList(uid int64, cookies Cookies, deviceID string) (medalRankLis
 		case "curUserRankIndex":
 			medalRankList.UserRank = string(v.GetStringBytes())
 		default:
-			log.Printf("指定主播的守护榜里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			defaultLogger().Warn("指定主播的守护榜里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -1327,18 +1356,21 @@ func getMedalRankList(uid int64, cookies Cookies, deviceID string) (medalRankLis
 }
 
 // 获取正在直播的直播间列表
-func getLiveList(count, page int, cookies Cookies, deviceID string) (liveList []UserLiveInfo, lastPage bool, e error) {
+func (t *token) getLiveList(ctx context.Context, count, page int) (liveList []UserLiveInfo, lastPage bool, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getLiveList() error: %v", err)
+			e = fmt.Errorf("getLiveList() error: %w", recoverErr(err))
 		}
 	}()
 
 	client := &httpClient{
-		url:      fmt.Sprintf(liveListURL, count, page),
-		method:   "GET",
-		cookies:  cookies,
-		deviceID: deviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       fmt.Sprintf(liveListURL, count, page),
+		method:    "GET",
+		cookies:   t.Cookies,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -1349,7 +1381,7 @@ func getLiveList(count, page int, cookies Cookies, deviceID string) (liveList []
 	checkErr(err)
 	v = v.Get("channelListData")
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取正在直播的直播间列表失败，响应为：%s", string(body)))
+		panic(newAPIError("获取正在直播的直播间列表失败", v, body))
 	}
 
 	list := v.GetArray("liveList")
@@ -1366,24 +1398,28 @@ func getLiveList(count, page int, cookies Cookies, deviceID string) (liveList []
 }
 
 // 获取全部正在直播的直播间列表
-func getAllLiveList(cookies Cookies, deviceID string) ([]UserLiveInfo, error) {
-	list, _, err := getLiveList(1000000, 0, cookies, deviceID)
+func (t *token) getAllLiveList(ctx context.Context) ([]UserLiveInfo, error) {
+	list, _, err := t.getLiveList(ctx, 1000000, 0)
 	return list, err
 }
 
 // 获取直播预告列表
 /*
-func getScheduleList(cookies Cookies) (scheduleList []LiveSchedule, e error) {
+func (t *token) getScheduleList(ctx context.Context) (scheduleList []LiveSchedule, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getScheduleList() error: %w", err)
+			e = fmt.Errorf("getScheduleList() error: %w", recoverErr(err))
 		}
 	}()
 
 	client := &httpClient{
+		client: t.client,
+		endpoints: t.endpoints,
+		ctx:     ctx,
 		url:     scheduleListURL,
 		method:  "POST",
-		cookies: cookies,
+		readOnly: true,
+		cookies: t.Cookies,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -1393,7 +1429,7 @@ func getScheduleList(cookies Cookies) (scheduleList []LiveSchedule, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取直播预告列表失败，响应为 %s", string(body)))
+		panic(newAPIError("获取直播预告列表失败", v, body))
 	}
 
 	list := v.GetArray("liveScheduleList")
@@ -1424,7 +1460,7 @@ func getScheduleList(cookies Cookies) (scheduleList []LiveSchedule, e error) {
 			case "reserveNumber":
 				scheduleList[i].ReserveNumber = v.GetInt()
 			default:
-				log.Printf("直播预告列表里出现未处理的 key 和 value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+				defaultLogger().Warn("直播预告列表里出现未处理的的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 			}
 		})
 		scheduleList[i].Profile.UserID = l.GetInt64("authorId")
@@ -1442,7 +1478,7 @@ func (pb *Playback) Distinguish() (aliURL, txURL string) {
 	case strings.Contains(pb.URL, "txvod"):
 		txURL = pb.URL
 	default:
-		log.Printf("未能识别的录播链接：%s", pb.URL)
+		defaultLogger().Warn("未能识别的录播链接", "url", pb.URL)
 	}
 
 	switch {
@@ -1451,7 +1487,7 @@ func (pb *Playback) Distinguish() (aliURL, txURL string) {
 	case strings.Contains(pb.BackupURL, "txvod"):
 		txURL = pb.BackupURL
 	default:
-		log.Printf("未能识别的录播链接：%s", pb.BackupURL)
+		defaultLogger().Warn("未能识别的录播链接", "url", pb.BackupURL)
 	}
 
 	return aliURL, txURL
@@ -1459,146 +1495,281 @@ func (pb *Playback) Distinguish() (aliURL, txURL string) {
 
 // GetWatchingList 返回直播间排名前 50 的在线观众信息列表
 func (ac *AcFunLive) GetWatchingList(liveID string) ([]WatchingUser, error) {
-	return ac.t.getWatchingList(liveID)
+	return ac.GetWatchingListCtx(context.Background(), liveID)
+}
+
+// GetWatchingListCtx 和 GetWatchingList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetWatchingListCtx(ctx context.Context, liveID string) ([]WatchingUser, error) {
+	return ac.t.getWatchingList(ctx, liveID)
 }
 
 // GetBillboard 返回指定 uid 的主播最近七日内的礼物贡献榜前 50 名观众的详细信息
 func (ac *AcFunLive) GetBillboard(uid int64) ([]BillboardUser, error) {
-	return ac.t.getBillboard(uid)
+	return ac.GetBillboardCtx(context.Background(), uid)
+}
+
+// GetBillboardCtx 和 GetBillboard() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetBillboardCtx(ctx context.Context, uid int64) ([]BillboardUser, error) {
+	return ac.t.getBillboard(ctx, uid)
 }
 
 // GetSummary 返回直播总结信息
 func (ac *AcFunLive) GetSummary(liveID string) (*Summary, error) {
-	return ac.t.getSummary(liveID)
+	return ac.GetSummaryCtx(context.Background(), liveID)
+}
+
+// GetSummaryCtx 和 GetSummary() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetSummaryCtx(ctx context.Context, liveID string) (*Summary, error) {
+	return ac.t.getSummary(ctx, liveID)
 }
 
 // GetLuckList 返回抢到红包的用户列表，需要登陆 AcFun 帐号，redpackBizUnit 为空时默认为 ztLiveAcfunRedpackGift
 func (ac *AcFunLive) GetLuckList(liveID, redpackID, redpackBizUnit string) ([]LuckyUser, error) {
-	return ac.t.getLuckList(liveID, redpackID, redpackBizUnit)
+	return ac.GetLuckListCtx(context.Background(), liveID, redpackID, redpackBizUnit)
+}
+
+// GetLuckListCtx 和 GetLuckList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetLuckListCtx(ctx context.Context, liveID, redpackID, redpackBizUnit string) ([]LuckyUser, error) {
+	return ac.t.getLuckList(ctx, liveID, redpackID, redpackBizUnit)
 }
 
 // GetPlayback 返回直播回放的相关信息，目前部分直播没有回放
 func (ac *AcFunLive) GetPlayback(liveID string) (*Playback, error) {
-	return ac.t.getPlayback(liveID)
+	return ac.GetPlaybackCtx(context.Background(), liveID)
+}
+
+// GetPlaybackCtx 和 GetPlayback() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetPlaybackCtx(ctx context.Context, liveID string) (*Playback, error) {
+	return ac.t.getPlayback(ctx, liveID)
 }
 
 // GetGiftList 返回指定主播直播间的礼物数据
 func (ac *AcFunLive) GetGiftList(liveID string) (map[int64]GiftDetail, error) {
-	return ac.t.getGiftList(liveID)
+	return ac.GetGiftListCtx(context.Background(), liveID)
+}
+
+// GetGiftListCtx 和 GetGiftList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetGiftListCtx(ctx context.Context, liveID string) (map[int64]GiftDetail, error) {
+	return ac.t.getGiftList(ctx, liveID)
 }
 
 // GetAllGiftList 返回全部礼物的数据
 func (ac *AcFunLive) GetAllGiftList() (map[int64]GiftDetail, error) {
-	return ac.t.getAllGift()
+	return ac.GetAllGiftListCtx(context.Background())
+}
+
+// GetAllGiftListCtx 和 GetAllGiftList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetAllGiftListCtx(ctx context.Context) (map[int64]GiftDetail, error) {
+	return ac.t.getAllGift(ctx)
 }
 
 // GetWalletBalance 返回钱包里 AC 币和拥有的香蕉的数量，需要登陆 AcFun 帐号
 func (ac *AcFunLive) GetWalletBalance() (accoins int, bananas int, e error) {
-	return ac.t.getWalletBalance()
+	return ac.GetWalletBalanceCtx(context.Background())
+}
+
+// GetWalletBalanceCtx 和 GetWalletBalance() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetWalletBalanceCtx(ctx context.Context) (accoins int, bananas int, e error) {
+	return ac.t.getWalletBalance(ctx)
 }
 
 // GetKickHistory 返回主播正在直播的那一场踢人的历史记录，count 为每页的数量，page 为第几页（从 0 开始数起），lastPage 说明是否最后一页，需要登陆主播的 AcFun 帐号
 func (ac *AcFunLive) GetKickHistory(liveID string, count, page int) (list []KickHistory, lastPage bool, e error) {
-	return ac.t.getKickHistory(liveID, count, page)
+	return ac.GetKickHistoryCtx(context.Background(), liveID, count, page)
+}
+
+// GetKickHistoryCtx 和 GetKickHistory() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetKickHistoryCtx(ctx context.Context, liveID string, count, page int) (list []KickHistory, lastPage bool, e error) {
+	return ac.t.getKickHistory(ctx, liveID, count, page)
 }
 
 // GetAllKickHistory 返回主播正在直播的那一场踢人的全部历史记录，需要登陆主播的 AcFun 帐号
 func (ac *AcFunLive) GetAllKickHistory(liveID string) ([]KickHistory, error) {
-	list, _, err := ac.t.getKickHistory(liveID, 1000000, 0)
+	return ac.GetAllKickHistoryCtx(context.Background(), liveID)
+}
+
+// GetAllKickHistoryCtx 和 GetAllKickHistory() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetAllKickHistoryCtx(ctx context.Context, liveID string) ([]KickHistory, error) {
+	list, _, err := ac.t.getKickHistory(ctx, liveID, 1000000, 0)
 	return list, err
 }
 
 // GetManagerList 返回主播的房管列表，需要登陆主播的 AcFun 帐号
 func (ac *AcFunLive) GetManagerList() ([]Manager, error) {
-	return ac.t.getManagerList()
+	return ac.GetManagerListCtx(context.Background())
+}
+
+// GetManagerListCtx 和 GetManagerList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetManagerListCtx(ctx context.Context) ([]Manager, error) {
+	return ac.t.getManagerList(ctx)
 }
 
 // GetMedalDetail 返回登陆帐号拥有的指定主播的守护徽章详细信息，需要登陆 AcFun 帐号
 func (ac *AcFunLive) GetMedalDetail(uid int64) (*MedalDetail, error) {
-	return ac.t.getMedalDetail(uid)
+	return ac.GetMedalDetailCtx(context.Background(), uid)
+}
+
+// GetMedalDetailCtx 和 GetMedalDetail() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetMedalDetailCtx(ctx context.Context, uid int64) (*MedalDetail, error) {
+	return ac.t.getMedalDetail(ctx, uid)
 }
 
 // GetMedalList 返回登陆用户拥有的守护徽章列表，最多返回亲密度最高的 300 个，需要登陆 AcFun 帐号
 func (ac *AcFunLive) GetMedalList() ([]Medal, error) {
-	return ac.t.getMedalList()
+	return ac.GetMedalListCtx(context.Background())
+}
+
+// GetMedalListCtx 和 GetMedalList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetMedalListCtx(ctx context.Context) ([]Medal, error) {
+	return ac.t.getMedalList(ctx)
 }
 
 // GetLiveData 返回前 days 日到目前为止所有直播的统计数据，需要登陆主播的 AcFun 帐号
 func (ac *AcFunLive) GetLiveData(days int) (*LiveData, error) {
-	return ac.t.getLiveData(days)
+	return ac.GetLiveDataCtx(context.Background(), days)
+}
+
+// GetLiveDataCtx 和 GetLiveData() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetLiveDataCtx(ctx context.Context, days int) (*LiveData, error) {
+	return ac.t.getLiveData(ctx, days)
 }
 
 // GetLiveCutInfo 获取 uid 指定主播的直播剪辑信息，只在主播直播时才能请求，需要直播的 liveID，需要登陆 AcFun 帐号
 func (ac *AcFunLive) GetLiveCutInfo(uid int64, liveID string) (*LiveCutInfo, error) {
-	return ac.t.getLiveCutInfo(uid, liveID)
+	return ac.GetLiveCutInfoCtx(context.Background(), uid, liveID)
+}
+
+// GetLiveCutInfoCtx 和 GetLiveCutInfo() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetLiveCutInfoCtx(ctx context.Context, uid int64, liveID string) (*LiveCutInfo, error) {
+	return ac.t.getLiveCutInfo(ctx, uid, liveID)
 }
 
 // GetUserLiveInfo 返回 uid 指定用户的直播信息，可能会出现超时等各种网络原因的错误
 func (ac *AcFunLive) GetUserLiveInfo(uid int64) (*UserLiveInfo, error) {
-	return getUserLiveInfo(uid, ac.t.Cookies, ac.t.DeviceID)
+	return ac.GetUserLiveInfoCtx(context.Background(), uid)
+}
+
+// GetUserLiveInfoCtx 和 GetUserLiveInfo() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetUserLiveInfoCtx(ctx context.Context, uid int64) (*UserLiveInfo, error) {
+	return ac.t.getUserLiveInfo(ctx, uid)
 }
 
 // GetUserInfo 返回 uid 指定用户的信息
 func (ac *AcFunLive) GetUserInfo(uid int64) (*UserProfileInfo, error) {
-	return getUserInfo(uid, ac.t.Cookies, ac.t.DeviceID)
+	return ac.GetUserInfoCtx(context.Background(), uid)
+}
+
+// GetUserInfoCtx 和 GetUserInfo() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetUserInfoCtx(ctx context.Context, uid int64) (*UserProfileInfo, error) {
+	return ac.t.getUserInfo(ctx, uid)
 }
 
 // GetMedalRankList 返回 uid 指定主播的守护榜（守护徽章亲密度排名前 50 名的用户），可用于获取指定主播的守护徽章名字
 func (ac *AcFunLive) GetMedalRankList(uid int64) (medalRankList *MedalRankList, e error) {
-	return getMedalRankList(uid, ac.t.Cookies, ac.t.DeviceID)
+	return ac.GetMedalRankListCtx(context.Background(), uid)
+}
+
+// GetMedalRankListCtx 和 GetMedalRankList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetMedalRankListCtx(ctx context.Context, uid int64) (medalRankList *MedalRankList, e error) {
+	return ac.t.getMedalRankList(ctx, uid)
 }
 
 // GetLiveList 返回正在直播的直播间列表，count 为每页的直播间数量，page 为第几页（从 0 开始数起），lastPage 说明是否最后一页
 func (ac *AcFunLive) GetLiveList(count, page int) (liveList []UserLiveInfo, lastPage bool, err error) {
-	return getLiveList(count, page, ac.t.Cookies, ac.t.DeviceID)
+	return ac.GetLiveListCtx(context.Background(), count, page)
+}
+
+// GetLiveListCtx 和 GetLiveList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetLiveListCtx(ctx context.Context, count, page int) (liveList []UserLiveInfo, lastPage bool, err error) {
+	return ac.t.getLiveList(ctx, count, page)
 }
 
 // GetAllLiveList 返回全部正在直播的直播间列表
 func (ac *AcFunLive) GetAllLiveList() ([]UserLiveInfo, error) {
-	return getAllLiveList(ac.t.Cookies, ac.t.DeviceID)
+	return ac.GetAllLiveListCtx(context.Background())
+}
+
+// GetAllLiveListCtx 和 GetAllLiveList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetAllLiveListCtx(ctx context.Context) ([]UserLiveInfo, error) {
+	return ac.t.getAllLiveList(ctx)
 }
 
 // GetScheduleList 返回直播预告列表，目前有问题不可用
 //func (ac *AcFunLive) GetScheduleList() ([]LiveSchedule, error) {
-//	return getScheduleList(ac.t.Cookies)
+//	return ac.t.getScheduleList(context.Background())
 //}
 
 // GetDeviceID 获取设备 ID
 func GetDeviceID() (string, error) {
-	return getDeviceID()
+	return GetDeviceIDCtx(context.Background())
+}
+
+// GetDeviceIDCtx 和 GetDeviceID() 一样，ctx 可以用来取消请求
+func GetDeviceIDCtx(ctx context.Context) (string, error) {
+	return new(token).getDeviceID(ctx)
 }
 
 // GetUserMedal 返回 uid 指定用户正在佩戴的守护徽章信息，没有 FriendshipDegree、JoinClubTime 和 CurrentDegreeLimit
 func GetUserMedal(uid int64, deviceID string) (medal *Medal, e error) {
-	return getUserMedal(uid, deviceID)
+	return GetUserMedalCtx(context.Background(), uid, deviceID)
+}
+
+// GetUserMedalCtx 和 GetUserMedal() 一样，ctx 可以用来取消请求
+func GetUserMedalCtx(ctx context.Context, uid int64, deviceID string) (medal *Medal, e error) {
+	return newDeviceToken(deviceID).getUserMedal(ctx, uid)
 }
 
 // GetUserLiveInfo 返回 uid 指定用户的直播信息，可能会出现超时等各种网络原因的错误
 func GetUserLiveInfo(uid int64, deviceID string) (*UserLiveInfo, error) {
-	return getUserLiveInfo(uid, nil, deviceID)
+	return GetUserLiveInfoCtx(context.Background(), uid, deviceID)
+}
+
+// GetUserLiveInfoCtx 和 GetUserLiveInfo() 一样，ctx 可以用来取消请求
+func GetUserLiveInfoCtx(ctx context.Context, uid int64, deviceID string) (*UserLiveInfo, error) {
+	return newDeviceToken(deviceID).getUserLiveInfo(ctx, uid)
 }
 
 // GetUserInfo 返回 uid 指定用户的信息
 func GetUserInfo(uid int64, deviceID string) (*UserProfileInfo, error) {
-	return getUserInfo(uid, nil, deviceID)
+	return GetUserInfoCtx(context.Background(), uid, deviceID)
+}
+
+// GetUserInfoCtx 和 GetUserInfo() 一样，ctx 可以用来取消请求
+func GetUserInfoCtx(ctx context.Context, uid int64, deviceID string) (*UserProfileInfo, error) {
+	return newDeviceToken(deviceID).getUserInfo(ctx, uid)
 }
 
 // GetMedalRankList 返回 uid 指定主播的守护榜（守护徽章亲密度排名前 50 名的用户），可用于获取指定主播的守护徽章名字
 func GetMedalRankList(uid int64, deviceID string) (medalRankList *MedalRankList, e error) {
-	return getMedalRankList(uid, nil, deviceID)
+	return GetMedalRankListCtx(context.Background(), uid, deviceID)
+}
+
+// GetMedalRankListCtx 和 GetMedalRankList() 一样，ctx 可以用来取消请求
+func GetMedalRankListCtx(ctx context.Context, uid int64, deviceID string) (medalRankList *MedalRankList, e error) {
+	return newDeviceToken(deviceID).getMedalRankList(ctx, uid)
 }
 
 // GetLiveList 返回正在直播的直播间列表，count 为每页的直播间数量，page 为第几页（从 0 开始数起），lastPage 说明是否最后一页
 func GetLiveList(count, page int, deviceID string) (liveList []UserLiveInfo, lastPage bool, err error) {
-	return getLiveList(count, page, nil, deviceID)
+	return GetLiveListCtx(context.Background(), count, page, deviceID)
+}
+
+// GetLiveListCtx 和 GetLiveList() 一样，ctx 可以用来取消请求
+func GetLiveListCtx(ctx context.Context, count, page int, deviceID string) (liveList []UserLiveInfo, lastPage bool, err error) {
+	return newDeviceToken(deviceID).getLiveList(ctx, count, page)
 }
 
 // GetAllLiveList 返回全部正在直播的直播间列表
 func GetAllLiveList(deviceID string) ([]UserLiveInfo, error) {
-	return getAllLiveList(nil, deviceID)
+	return GetAllLiveListCtx(context.Background(), deviceID)
+}
+
+// GetAllLiveListCtx 和 GetAllLiveList() 一样，ctx 可以用来取消请求
+func GetAllLiveListCtx(ctx context.Context, deviceID string) ([]UserLiveInfo, error) {
+	return newDeviceToken(deviceID).getAllLiveList(ctx)
 }
 
 // GetScheduleList 返回直播预告列表，目前有问题不可用
 //func GetScheduleList() ([]LiveSchedule, error) {
-//	return getScheduleList(nil)
+//	return new(token).getScheduleList(context.Background())
 //}
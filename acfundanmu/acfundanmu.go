@@ -1,21 +1,28 @@
 package acfundanmu
 
 import (
+	"bufio"
 	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"slices"
 	"sync"
+	"time"
 
-	"github.com/Workiva/go-datastructures/queue"
 	"github.com/orzogc/acfundanmu/acproto"
 	"github.com/segmentio/encoding/json"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fastjson"
+	"go.uber.org/atomic"
 )
 
 // 弹幕队列长度
 const queueLen = 100
 
+// 弹幕服务器没有指定时的心跳间隔，单位为毫秒
+const defaultHeartbeatInterval = 10000
+
 // ManagerType 就是房管类型
 type ManagerType int32
 
@@ -40,6 +47,80 @@ const (
 	IsManager
 )
 
+// LiveFeatureType 就是直播间功能的类型
+type LiveFeatureType int32
+
+const (
+	// LiveFeatureUnknown 未知的功能
+	LiveFeatureUnknown LiveFeatureType = iota
+	// LiveFeatureLandscapeComment 横屏弹幕
+	LiveFeatureLandscapeComment
+)
+
+// LiveFeatureStateType 就是直播间功能的开关状态
+type LiveFeatureStateType int32
+
+const (
+	// LiveFeatureStateUnknown 未知的状态
+	LiveFeatureStateUnknown LiveFeatureStateType = iota
+	// LiveFeatureStateOpened 功能已开启
+	LiveFeatureStateOpened
+	// LiveFeatureStateClosed 功能已关闭
+	LiveFeatureStateClosed
+)
+
+// CoverAuditResultType 就是直播间封面和标题的审核结果
+type CoverAuditResultType int32
+
+const (
+	// CoverAuditSuccess 审核通过
+	CoverAuditSuccess CoverAuditResultType = iota
+	// CoverAuditFailed 封面审核不通过
+	CoverAuditFailed
+	// CaptionAuditFailed 标题审核不通过
+	CaptionAuditFailed
+	// CoverAndCaptionAuditFailed 封面和标题审核都不通过
+	CoverAndCaptionAuditFailed
+)
+
+// SignalLayer 就是弹幕数据所在的层级
+type SignalLayer int32
+
+const (
+	// SignalLayerCommand DownstreamPayload 的 Command
+	SignalLayerCommand SignalLayer = iota
+	// SignalLayerCmdAck ZtLiveCsCmdAck 的 CmdAckType
+	SignalLayerCmdAck
+	// SignalLayerMessage ZtLiveScMessage 的 MessageType
+	SignalLayerMessage
+	// SignalLayerAction Action Signal 的 SignalType
+	SignalLayerAction
+	// SignalLayerState State Signal 的 SignalType
+	SignalLayerState
+	// SignalLayerNotify Notify Signal 的 SignalType
+	SignalLayerNotify
+)
+
+// String 返回层级的名字
+func (l SignalLayer) String() string {
+	switch l {
+	case SignalLayerCommand:
+		return "stream.Command"
+	case SignalLayerCmdAck:
+		return "cmd.CmdAckType"
+	case SignalLayerMessage:
+		return "message.MessageType"
+	case SignalLayerAction:
+		return "Action Signal item.SignalType"
+	case SignalLayerState:
+		return "State Signal item.SignalType"
+	case SignalLayerNotify:
+		return "Notify Signal signalType"
+	default:
+		return fmt.Sprintf("SignalLayer(%d)", int32(l))
+	}
+}
+
 // SharePlatformType 就是分享的平台类型
 type SharePlatformType int32
 
@@ -194,11 +275,11 @@ func NewUserInfo(userInfo *acproto.ZtLiveUserInfo) *UserInfo {
 				case "level":
 					user.Medal.Level = v.GetInt()
 				default:
-					log.Printf("守护徽章里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+					defaultLogger().Warn("守护徽章里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 				}
 			})
 		} else {
-			log.Printf("分析守护徽章的json数据出现错误：%v", err)
+			defaultLogger().Warn("分析守护徽章的json数据出现错误", "error", err)
 		}
 	}
 
@@ -307,6 +388,12 @@ type ShareLive struct {
 	SharePlatformIcon string            `json:"sharePlatformIcon"` // 将直播间分享到的平台的图标
 }
 
+// EventMessage 非弹幕的事件，设置 SetQueueAllEvents(true) 后会放入 GetDanmu() 的队列，如连麦、红包和直播间状态等
+type EventMessage struct {
+	Event       `json:"event"`
+	ReceiveTime int64 `json:"receiveTime"` // 收到事件的时间，是以毫秒为单位的 Unix 时间
+}
+
 // TopUser 就是礼物榜在线前三，目前没有 Medal 和 ManagerType
 type TopUser WatchingUser
 
@@ -389,6 +476,277 @@ type AuthorChatChangeSoundConfig struct {
 	SoundConfigChangeType SoundConfigChangeType `json:"soundConfigChangeType"` // 声音设置更改的类型
 }
 
+// EventDispatchMode 事件响应模式下事件的分发方式
+type EventDispatchMode uint8
+
+const (
+	// DispatchConcurrent 并行分发事件（默认）。数据包由固定数量的 goroutine（默认为 4 个，见 SetWorkerPoolSize()）处理，
+	// 每个事件 handler 都在单独的 goroutine 里调用，不同数据包的事件和同一个事件的不同 handler 之间都不保证顺序
+	DispatchConcurrent EventDispatchMode = iota
+	// DispatchOrdered 有序分发事件，每个直播间只用一个 goroutine 按接收顺序处理数据包并依次调用事件 handler
+	DispatchOrdered
+)
+
+// ReconnectPolicy 弹幕连接断开后自动重连的策略
+type ReconnectPolicy struct {
+	MaxAttempts     int           // 连续重连的最大次数，小于等于 0 时不限制次数，重连成功后重新计数
+	InitialInterval time.Duration // 第一次重连前等待的时间
+	MaxInterval     time.Duration // 重连前等待的最长时间，为 0 时不限制
+	Multiplier      float64       // 每次重连失败后等待时间的倍数，小于 1 时按 1 处理
+}
+
+// Reconnecting 弹幕连接断开后准备重连的信息
+type Reconnecting struct {
+	Attempt int           // 第几次连续重连，从 1 开始
+	Delay   time.Duration // 重连前等待的时间
+	Err     error         // 导致重连的错误
+}
+
+// 第 attempt 次重连前等待的时间
+func (p *ReconnectPolicy) delay(attempt int) time.Duration {
+	d := float64(p.InitialInterval)
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+		if p.MaxInterval > 0 && d >= float64(p.MaxInterval) {
+			return p.MaxInterval
+		}
+	}
+	if p.MaxInterval > 0 && d > float64(p.MaxInterval) {
+		return p.MaxInterval
+	}
+
+	return time.Duration(d)
+}
+
+// LiveStatusType 直播状态变化的类型
+type LiveStatusType int32
+
+const (
+	// LiveStatusUnknown 未知的直播状态变化
+	LiveStatusUnknown LiveStatusType = iota
+	// LiveStatusClosed 主播下播
+	LiveStatusClosed
+	// LiveStatusNewLiveOpened 主播开始了新的直播？
+	LiveStatusNewLiveOpened
+	// LiveStatusURLChanged 直播源链接改变
+	LiveStatusURLChanged
+	// LiveStatusBanned 直播间被封禁
+	LiveStatusBanned
+)
+
+// LiveStatusChange 直播状态变化
+type LiveStatusChange struct {
+	Type           LiveStatusType `json:"type"`           // 直播状态变化的类型
+	MaxRandomDelay int64          `json:"maxRandomDelay"` // 重新获取直播信息前随机等待的最长时间？单位为毫秒
+	BanReason      string         `json:"banReason"`      // 直播间被封禁的理由
+}
+
+// AuthorPause 主播暂停直播
+type AuthorPause struct {
+	PauseTime int64  `json:"pauseTime"` // 暂停直播的时间，是以毫秒为单位的 Unix 时间
+	Reason    string `json:"reason"`    // 暂停直播的理由？
+}
+
+// AuthorResume 主播恢复直播
+type AuthorResume struct {
+	ResumeTime int64 `json:"resumeTime"` // 恢复直播的时间，是以毫秒为单位的 Unix 时间
+}
+
+// UnknownSignal 未知的弹幕数据
+type UnknownSignal struct {
+	Layer   SignalLayer `json:"layer"`   // 弹幕数据所在的层级
+	Type    string      `json:"type"`    // 弹幕数据的类型名字
+	Payload []byte      `json:"payload"` // 弹幕数据未解码的原始数据
+}
+
+// ApplyUser 申请连麦的观众
+type ApplyUser struct {
+	UserID int64 `json:"userID"` // 申请连麦的观众的 uid？
+}
+
+// RemoveApplyUser 观众离开连麦申请队列
+type RemoveApplyUser struct {
+	UserID int64 `json:"userID"` // 离开连麦申请队列的观众的 uid？
+	Reason int32 `json:"reason"` // 离开队列的原因，具体含义未知
+}
+
+// LiveFeature 直播间功能的开关状态
+type LiveFeature struct {
+	Type  LiveFeatureType      `json:"type"`  // 功能类型
+	State LiveFeatureStateType `json:"state"` // 功能的开关状态
+}
+
+// Widget 直播间挂件
+type Widget struct {
+	WidgetID      int64  `json:"widgetID"`      // 挂件 ID
+	Name          string `json:"name"`          // 挂件名字？
+	Type          int32  `json:"type"`          // 挂件类型？
+	StartTime     int64  `json:"startTime"`     // 挂件开始显示的时间？是以毫秒为单位的 Unix 时间
+	EndTime       int64  `json:"endTime"`       // 挂件结束显示的时间？是以毫秒为单位的 Unix 时间
+	Picture       string `json:"picture"`       // 挂件图片
+	PictureWidth  int32  `json:"pictureWidth"`  // 挂件图片宽度
+	PictureHeight int32  `json:"pictureHeight"` // 挂件图片高度
+	URL           string `json:"url"`           // 点击挂件跳转的链接？
+}
+
+// Wish 心愿单里的单个心愿
+type Wish struct {
+	WishID       string `json:"wishID"`       // 心愿 ID
+	GiftID       int64  `json:"giftID"`       // 心愿的礼物 ID？
+	CurrentCount int64  `json:"currentCount"` // 目前收到的礼物数量？
+	TargetCount  int64  `json:"targetCount"`  // 心愿的目标礼物数量？
+	Description  string `json:"description"`  // 心愿描述？
+	Extra        string `json:"extra"`
+}
+
+// WishSheet 主播的心愿单
+type WishSheet struct {
+	WishSheetID string `json:"wishSheetID"` // 心愿单 ID
+	Wishes      []Wish `json:"wishes"`      // 心愿列表
+}
+
+// TopBanner 直播间顶部的横幅公告
+type TopBanner struct {
+	BannerID        string `json:"bannerID"`        // 横幅 ID？
+	Title           string `json:"title"`           // 横幅标题？
+	Content         string `json:"content"`         // 横幅内容？
+	BackgroundColor string `json:"backgroundColor"` // 横幅背景颜色
+	BackgroundImage string `json:"backgroundImage"` // 横幅背景图片
+	Type            int32  `json:"type"`            // 横幅类型？
+	ButtonText      string `json:"buttonText"`      // 横幅按钮的文字
+	ButtonURL       string `json:"buttonURL"`       // 横幅按钮的链接？
+	Icon            string `json:"icon"`            // 横幅图标
+	DisplayDuration int64  `json:"displayDuration"` // 横幅显示的时长？单位为毫秒
+}
+
+// ShoppingCart 直播间购物车状态
+type ShoppingCart struct {
+	State int32  `json:"state"` // 购物车状态，具体含义未知
+	Data  string `json:"data"`  // 购物车相关数据？
+}
+
+// EcommerceCart 直播间电商购物车状态
+type EcommerceCart struct {
+	Show bool `json:"show"` // 是否显示购物车？
+}
+
+// EcommerceCartItemPopup 直播间电商购物车弹出的商品
+type EcommerceCartItemPopup struct {
+	Show    bool   `json:"show"`    // 是否显示商品弹窗？
+	ItemID  string `json:"itemID"`  // 商品 ID？
+	Title   string `json:"title"`   // 商品标题？
+	Price   string `json:"price"`   // 商品价格？
+	Picture string `json:"picture"` // 商品图片？
+}
+
+// PKEndType PK 结束类型，具体含义未知
+type PKEndType int32
+
+// PKPlayerInfo PK 的主播信息
+type PKPlayerInfo struct {
+	UserInfo               `json:"userInfo"`
+	LiveID                 string `json:"liveID"`                 // 直播 ID
+	EnableJumpPeerLiveRoom bool   `json:"enableJumpPeerLiveRoom"` // 允许跳转到 PK 对手的直播间？
+}
+
+// PKInvitation 主播发起 PK
+type PKInvitation struct {
+	PKID       string       `json:"pkID"`       // PK ID
+	Inviter    PKPlayerInfo `json:"inviter"`    // 发起 PK 的主播的信息
+	InviteTime int64        `json:"inviteTime"` // PK 发起时间，是以毫秒为单位的 Unix 时间
+}
+
+// PKAccept 主播接受 PK
+type PKAccept struct {
+	PKID       string `json:"pkID"` // PK ID
+	SignalInfo string `json:"signalInfo"`
+}
+
+// PKReady PK 准备开始，Players 是参与 PK 的主播
+type PKReady struct {
+	PKID    string         `json:"pkID"`    // PK ID
+	Players []PKPlayerInfo `json:"players"` // 参与 PK 的主播的信息
+}
+
+// PKEnd PK 结束
+type PKEnd struct {
+	PKID      string    `json:"pkID"`      // PK ID
+	EndType   PKEndType `json:"endType"`   // PK 结束类型
+	EndLiveID string    `json:"endLiveID"` // 结束 PK 的直播 ID？
+}
+
+// PKSoundConfigChanged PK 时主播更改声音设置
+type PKSoundConfigChanged struct {
+	PKID                  string                `json:"pkID"`                  // PK ID
+	SoundConfigChangeType SoundConfigChangeType `json:"soundConfigChangeType"` // 声音设置更改的类型
+}
+
+// PKRoundInfo PK 回合信息
+type PKRoundInfo struct {
+	BeginTime    int64 `json:"beginTime"`    // 回合开始的时间？是以毫秒为单位的 Unix 时间
+	CurrentRound int32 `json:"currentRound"` // 目前是第几回合
+	TotalRound   int32 `json:"totalRound"`   // 总回合数
+	EndTime      int64 `json:"endTime"`      // 回合结束的时间？是以毫秒为单位的 Unix 时间
+	State        int32 `json:"state"`        // 回合状态？
+}
+
+// PKPlayerRoundScore PK 主播单个回合的得分
+type PKPlayerRoundScore struct {
+	Score int64 `json:"score"` // 得分
+	Round int32 `json:"round"` // 第几回合
+}
+
+// PKPlayerStatistic PK 主播的得分
+type PKPlayerStatistic struct {
+	UserInfo    `json:"userInfo"`
+	LiveID      string               `json:"liveID"`      // 直播 ID
+	Score       int64                `json:"score"`       // 总得分
+	RoundScores []PKPlayerRoundScore `json:"roundScores"` // 各回合的得分
+}
+
+// PKContributor PK 时给主播助攻的观众
+type PKContributor struct {
+	UserInfo     `json:"userInfo"`
+	Contribution int64 `json:"contribution"` // 助攻的分数
+}
+
+// PKContribution PK 时主播的观众助攻榜
+type PKContribution struct {
+	UserID       int64           `json:"userID"`       // 主播的 uid？
+	Contributors []PKContributor `json:"contributors"` // 助攻的观众
+}
+
+// PKStatistic PK 的统计数据
+type PKStatistic struct {
+	PKID          string              `json:"pkID"`          // PK ID
+	BeginTime     int64               `json:"beginTime"`     // PK 开始的时间？是以毫秒为单位的 Unix 时间
+	Duration      int64               `json:"duration"`      // PK 时长？单位为毫秒
+	Players       []PKPlayerStatistic `json:"players"`       // 参与 PK 的主播的得分
+	Contributions []PKContribution    `json:"contributions"` // 观众助攻榜
+	Round         PKRoundInfo         `json:"round"`         // 目前的回合信息
+}
+
+// 深复制 PKStatistic
+func (s PKStatistic) copy() PKStatistic {
+	players := make([]PKPlayerStatistic, len(s.Players))
+	for i, player := range s.Players {
+		player.RoundScores = append([]PKPlayerRoundScore{}, player.RoundScores...)
+		players[i] = player
+	}
+	s.Players = players
+	contributions := make([]PKContribution, len(s.Contributions))
+	for i, contribution := range s.Contributions {
+		contribution.Contributors = append([]PKContributor{}, contribution.Contributors...)
+		contributions[i] = contribution
+	}
+	s.Contributions = contributions
+	return s
+}
+
 // QRCode 登陆二维码
 type QRCode struct {
 	ExpireTime int64  `json:"expireTime"` // 二维码失效时间，是以毫秒为单位的 Unix 时间
@@ -437,11 +795,22 @@ type LiveInfo struct {
 	KickedOut        string       `json:"kickedOut"`        // 被踢理由？
 	ViolationAlert   string       `json:"violationAlert"`   // 直播间警告？
 	LiveManagerState ManagerState `json:"liveManagerState"` // 登陆帐号的房管状态
+	AuthorPaused     bool         `json:"authorPaused"`     // 主播是否暂停了直播
 	AllBananaCount   string       `json:"allBananaCount"`   // 直播间香蕉总数
 	DisplayInfo      `json:"displayInfo"`
-	TopUsers         []TopUser `json:"topUsers"`      // 礼物榜在线前三
-	RecentComment    []Comment `json:"recentComment"` // APP 进直播间时显示的最近发的弹幕
-	RedpackList      []Redpack `json:"redpackList"`   // 红包列表
+	TopUsers         []TopUser              `json:"topUsers"`         // 礼物榜在线前三
+	RecentComment    []Comment              `json:"recentComment"`    // APP 进直播间时显示的最近发的弹幕
+	RedpackList      []Redpack              `json:"redpackList"`      // 红包列表
+	PKStatistic      PKStatistic            `json:"pkStatistic"`      // 最近一场 PK 的统计数据
+	Widgets          []Widget               `json:"widgets"`          // 直播间挂件
+	WishSheet        WishSheet              `json:"wishSheet"`        // 主播的心愿单
+	TopBanner        TopBanner              `json:"topBanner"`        // 直播间顶部的横幅公告
+	ShoppingCart     ShoppingCart           `json:"shoppingCart"`     // 直播间购物车状态
+	EcommerceCart    EcommerceCart          `json:"ecommerceCart"`    // 直播间电商购物车状态
+	EcommerceItem    EcommerceCartItemPopup `json:"ecommerceItem"`    // 直播间电商购物车弹出的商品
+	ApplyUserIDs     []int64                `json:"applyUserIDs"`     // 连麦申请队列里观众的 uid
+	Features         []LiveFeature          `json:"features"`         // 直播间功能的开关状态
+	CoverAuditResult CoverAuditResultType   `json:"coverAuditResult"` // 最近一次直播间封面和标题的审核结果
 }
 
 // 带锁的 LiveInfo
@@ -453,11 +822,37 @@ type liveInfo struct {
 
 // AcFunLive 就是直播间弹幕系统相关信息，支持并行
 type AcFunLive struct {
-	q           *queue.Queue // DanmuMessage 的队列
-	info        *liveInfo    // 直播间的相关信息状态
-	t           *token       // 令牌相关信息
-	handlerMap  *handlerMap  // 事件 handler 的 map
-	danmuClient DanmuClient  // 弹幕客户端
+	q                *danmuQueue                 // DanmuMessage 的队列
+	info             *liveInfo                   // 直播间的相关信息状态
+	t                *token                      // 令牌相关信息
+	handlerMap       *handlerMap                 // 事件 handler 的 map
+	danmuClient      DanmuClient                 // 弹幕客户端
+	reconnect        *ReconnectPolicy            // 自动重连的策略
+	attempt          atomic.Int32                // 连续重连的次数
+	dispatchMode     EventDispatchMode           // 事件的分发方式
+	eventSeq         atomic.Uint64               // 最近分发的事件的序号
+	servers          []string                    // 弹幕服务器地址
+	accessPoints     accessPoints                // 弹幕服务器下发的接入点地址
+	capture          *captureWriter              // 抓取弹幕数据
+	replay           bool                        // 是否回放抓取文件，回放时不联网
+	quietUnknown     bool                        // 是否不输出未知弹幕数据的日志
+	stats            connStats                   // 弹幕连接的统计数据
+	stallLimit       int                         // 判定连接停滞的未收到回应的心跳数
+	stalled          atomic.Bool                 // 当前连接是否被判定为停滞
+	lastAlive        atomic.Int64                // 当前连接最近一次收到心跳回应或弹幕数据的时间
+	heartbeat        atomic.Int64                // 当前连接的心跳间隔，单位为毫秒
+	connGoroutines   sync.WaitGroup              // 当前连接的心跳和看门狗 goroutine
+	optionErr        error                       // 设置选项时出现的错误
+	workers          int                         // 处理弹幕数据的 goroutine 数量
+	stream           atomic.Pointer[eventStream] // Events() 的事件流
+	streamSize       int                         // Events() 的缓冲区大小
+	streamPolicy     OverflowPolicy              // Events() 的缓冲区满时的处理方式
+	eventMiddlewares []EventMiddleware           // 事件中间件
+	combos           giftCombos                  // 正在连击的礼物
+	orderedMu        sync.Mutex                  // 有序分发模式下保证事件 handler 依次调用
+	queueAll         bool                        // 非事件响应模式下是否将所有事件放入队列
+	queueSize        int                         // 队列的容量
+	queuePolicy      OverflowPolicy              // 队列满时的处理方式
 }
 
 // Option 就是 AcFunLive 的选项
@@ -504,6 +899,161 @@ func SetDanmuClient(client DanmuClient) Option {
 	}
 }
 
+// SetReconnectPolicy 设置弹幕连接断开后自动重连的策略，policy 为 nil 时不自动重连（默认）。
+// 重连时会重新连接弹幕服务器并换用下一个 ticket 进入直播间，事件处理函数和弹幕队列保持不变。
+func SetReconnectPolicy(policy *ReconnectPolicy) Option {
+	if policy == nil {
+		return func(ac *AcFunLive) {}
+	}
+	p := *policy
+	return func(ac *AcFunLive) {
+		ac.reconnect = &p
+	}
+}
+
+// SetServerAddresses 设置弹幕服务器地址，连接时按顺序尝试，连接失败时换用下一个地址。
+// 地址要和弹幕客户端的类型对应，WebSocket 为 URL（如 wss://example.com/），TCP 为 host:port，默认使用 AcFun 的弹幕服务器。
+// 这些地址都连接失败后会尝试弹幕服务器下发的接入点地址。
+func SetServerAddresses(addresses ...string) Option {
+	servers := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if address != "" {
+			servers = append(servers, address)
+		}
+	}
+	if len(servers) == 0 {
+		return func(ac *AcFunLive) {}
+	}
+	return func(ac *AcFunLive) {
+		ac.servers = servers
+	}
+}
+
+// SetCapture 设置抓取模式，将接收到的每条解码后的弹幕数据写入 w，写入的数据可以用 ReplayDanmuClient 回放。
+// 写入出现错误后会停止抓取，w 需要调用者自行关闭。
+func SetCapture(w io.Writer) Option {
+	if w == nil {
+		return func(ac *AcFunLive) {}
+	}
+	return func(ac *AcFunLive) {
+		ac.capture = &captureWriter{w: bufio.NewWriter(w)}
+	}
+}
+
+// SetLogger 设置日志的 handler，日志会带有主播 uid（liverUID）和 liveID 等字段。
+// 默认使用 slog.Default()，handler 为 nil 或 DiscardLogHandler 时不输出日志。
+// 没有 AcFunLive 的函数（如 Login()）使用 slog.Default()。
+func SetLogger(handler LogHandler) Option {
+	if handler == nil {
+		handler = DiscardLogHandler
+	}
+	logger := slog.New(handler)
+	return func(ac *AcFunLive) {
+		ac.t.log = logger
+	}
+}
+
+// SetUnknownSignalLog 设置是否在日志里输出未知的弹幕数据，默认输出，不影响 OnUnknownSignal 的调用
+func SetUnknownSignalLog(enable bool) Option {
+	return func(ac *AcFunLive) {
+		ac.quietUnknown = !enable
+	}
+}
+
+// SetStallWatchdog 设置弹幕连接停滞的看门狗，连续 missedHeartbeats 个心跳间隔没有收到心跳回应或其他弹幕数据时判定连接停滞。
+// 看门狗在连接弹幕服务器后就开始检查，进入直播间前的心跳间隔按 10 秒计算。
+// 连接停滞时会关闭连接，设置了 SetReconnectPolicy() 时会自动重连，否则弹幕获取以 ErrConnectionStalled 结束。
+// missedHeartbeats 小于等于 0 时不启用（默认）。
+func SetStallWatchdog(missedHeartbeats int) Option {
+	return func(ac *AcFunLive) {
+		ac.stallLimit = missedHeartbeats
+	}
+}
+
+// SetProxy 设置代理，用于这个 AcFunLive 的 HTTP 请求和弹幕连接，proxy 的格式见 SetDefaultProxy()。
+// 默认使用 SetDefaultProxy() 设置的全局代理，proxy 格式错误时 NewAcFunLive() 会返回错误。
+func SetProxy(proxy string) Option {
+	u, err := parseProxy(proxy)
+	return func(ac *AcFunLive) {
+		if err != nil {
+			ac.optionErr = err
+			return
+		}
+		ac.t.proxy = u
+	}
+}
+
+// SetHTTPClient 设置 HTTP 请求使用的客户端，client 为 nil 时使用默认的客户端。
+// 设置后 SetProxy() 和 SetDefaultProxy() 只用于弹幕连接，HTTP 请求的代理需要在 client 里设置。
+func SetHTTPClient(client *fasthttp.Client) Option {
+	return func(ac *AcFunLive) {
+		ac.t.client = client
+	}
+}
+
+// SetEndpoints 设置这个 AcFunLive 的 HTTP 请求要替换的 API 的 host，不设置时使用 SetDefaultEndpoints() 的全局设置，格式错误时 NewAcFunLive() 会返回错误
+func SetEndpoints(endpoints Endpoints) Option {
+	m, err := endpoints.parse()
+	return func(ac *AcFunLive) {
+		if err != nil {
+			ac.optionErr = err
+			return
+		}
+		ac.t.endpoints = m
+	}
+}
+
+// SetWorkerPoolSize 设置处理弹幕数据的 goroutine 数量，默认为 4，n 小于等于 0 时使用默认值。
+// 采用 DispatchOrdered 时总是只用一个 goroutine。
+func SetWorkerPoolSize(n int) Option {
+	return func(ac *AcFunLive) {
+		ac.workers = n
+	}
+}
+
+// SetEventStream 设置 Events() 返回的 channel 的缓冲区大小和缓冲区满时的处理方式，默认为 100 和 OverflowBlock，size 小于等于 0 时使用默认值。
+// 丢弃的事件数可以用 Stats() 获取。
+func SetEventStream(size int, policy OverflowPolicy) Option {
+	return func(ac *AcFunLive) {
+		ac.streamSize = size
+		ac.streamPolicy = policy
+	}
+}
+
+// SetEventMiddleware 设置事件中间件，第一个中间件最先调用，会替换之前设置的中间件。
+// 中间件也会用于 GetDanmu() 的队列，可以用来屏蔽用户或者修改弹幕内容等。
+func SetEventMiddleware(middlewares ...EventMiddleware) Option {
+	return func(ac *AcFunLive) {
+		ac.eventMiddlewares = slices.Clone(middlewares)
+	}
+}
+
+// SetQueue 设置非事件响应模式下 GetDanmu() 的队列的容量和队列满时的处理方式，丢弃的弹幕数可以用 Stats() 获取。
+// 默认容量为 10000，队列满时采用 OverflowDropLowPriority。
+// size 小于等于 0 时不限制容量，这时不及时调用 GetDanmu() 会导致占用的内存一直增长。
+func SetQueue(size int, policy OverflowPolicy) Option {
+	return func(ac *AcFunLive) {
+		ac.queueSize = size
+		ac.queuePolicy = policy
+	}
+}
+
+// SetQueueAllEvents 设置非事件响应模式下是否将所有事件放入 GetDanmu() 的队列，默认为 false，只有弹幕会放入队列。
+// 为 true 时非弹幕的事件会包装为 *EventMessage 放入队列，LiveInfo 仍然会更新。
+func SetQueueAllEvents(enable bool) Option {
+	return func(ac *AcFunLive) {
+		ac.queueAll = enable
+	}
+}
+
+// SetEventDispatchMode 设置事件响应模式下事件的分发方式，默认为 DispatchConcurrent。
+// 采用 DispatchOrdered 时事件 handler 会按事件接收的顺序依次调用，耗时的 handler 会阻塞之后的事件。
+func SetEventDispatchMode(mode EventDispatchMode) Option {
+	return func(ac *AcFunLive) {
+		ac.dispatchMode = mode
+	}
+}
+
 // MarshalJSON 实现 json 的 Marshaler 接口
 func (c Cookies) MarshalJSON() ([]byte, error) {
 	cookies := make([]string, 0, len(c))
@@ -542,7 +1092,7 @@ func Login(account, password string) (cookies Cookies, err error) {
 
 	cookies, err = login(account, password)
 	if err != nil {
-		log.Printf("登陆AcFun帐号失败：%v", err)
+		defaultLogger().Error("登陆AcFun帐号失败", "error", err)
 		return nil, fmt.Errorf("Login() error: 登陆 AcFun 帐号失败：%w", err)
 	}
 
@@ -555,18 +1105,25 @@ func LoginWithQRCode(qrCodeCallback func(QRCode), scannedCallback func()) (cooki
 	return loginWithQRCode(qrCodeCallback, scannedCallback)
 }
 
-// NewAcFunLive 新建一个 *AcFunLive
+// NewAcFunLive 新建一个 *AcFunLive，设置的主播没有在直播时 errors.Is(err, ErrNotLive) 为 true
+// 注意：非事件响应模式下 GetDanmu() 的队列默认容量为 10000，队列满时采用 OverflowDropLowPriority，
+// 会丢弃弹幕（优先丢弃进入直播间和点赞），不想丢弃弹幕的话可以用 SetQueue(0, OverflowBlock) 设置不限容量的队列
 func NewAcFunLive(options ...Option) (ac *AcFunLive, err error) {
 	ac = new(AcFunLive)
 	ac.info = new(liveInfo)
 	ac.t = new(token)
 	ac.t.livePage = liveHost
 	ac.handlerMap = new(handlerMap)
-	ac.handlerMap.listMap = make(map[eventType][]eventHandler)
+	ac.handlerMap.listMap = make(map[EventType][]handlerEntry)
+	ac.queueSize = defaultQueueSize
+	ac.queuePolicy = OverflowDropLowPriority
 
 	for _, option := range options {
 		option(ac)
 	}
+	if ac.optionErr != nil {
+		return nil, fmt.Errorf("NewAcFunLive() error: 选项错误：%w", ac.optionErr)
+	}
 
 	// 默认弹幕客户端使用 WebSocket 连接
 	if ac.danmuClient == nil {
@@ -574,13 +1131,22 @@ func NewAcFunLive(options ...Option) (ac *AcFunLive, err error) {
 		//ac.danmuClient = &TCPDanmuClient{}
 	}
 
+	if ac.t.proxy != nil {
+		if ac.t.client == nil {
+			ac.t.client = newProxyClient(ac.t.proxy)
+		}
+		if client, ok := ac.danmuClient.(proxySetter); ok {
+			client.setProxy(ac.t.proxy)
+		}
+	}
+
 	if ac.t.UserID == 0 {
 		ac.info.StreamInfo, err = ac.t.getToken()
 	} else {
 		ac.info.StreamInfo, err = ac.t.getLiveToken()
 	}
 	if err != nil {
-		log.Printf("初始化失败：%v", err)
+		ac.t.logger().Error("初始化失败", "error", err)
 		return nil, fmt.Errorf("NewAcFunLive() error: 初始化失败：%w", err)
 	}
 
@@ -593,7 +1159,27 @@ func (ac *AcFunLive) SetLiverUID(uid int64) (newAC *AcFunLive, err error) {
 		return nil, fmt.Errorf("主播 uid 不能小于 1")
 	}
 	tokenInfo := ac.GetTokenInfo()
-	newAC, err = NewAcFunLive(SetLiverUID(uid), SetTokenInfo(tokenInfo), SetDanmuClient(ac.danmuClient.NewDanmuClient()))
+	newAC, err = NewAcFunLive(
+		SetLiverUID(uid),
+		SetTokenInfo(tokenInfo),
+		SetDanmuClient(ac.danmuClient.NewDanmuClient()),
+		SetReconnectPolicy(ac.reconnect),
+		SetEventDispatchMode(ac.dispatchMode),
+		SetServerAddresses(ac.servers...),
+		SetUnknownSignalLog(!ac.quietUnknown),
+		SetStallWatchdog(ac.stallLimit),
+		SetWorkerPoolSize(ac.workers),
+		SetEventStream(ac.streamSize, ac.streamPolicy),
+		SetEventMiddleware(ac.eventMiddlewares...),
+		SetQueueAllEvents(ac.queueAll),
+		SetQueue(ac.queueSize, ac.queuePolicy),
+		func(newAC *AcFunLive) {
+			newAC.t.log = ac.t.log
+			newAC.t.proxy = ac.t.proxy
+			newAC.t.client = ac.t.client
+			newAC.t.endpoints = ac.t.endpoints
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -601,60 +1187,51 @@ func (ac *AcFunLive) SetLiverUID(uid int64) (newAC *AcFunLive, err error) {
 	return newAC, nil
 }
 
-// CopyEventHandlers 弹幕获取采用事件响应模式时复制 anotherAC 的事件处理函数到 ac
+// CopyEventHandlers 弹幕获取采用事件响应模式时复制 anotherAC 的事件处理函数到 ac，会覆盖 ac 原有的同类事件处理函数。
+// 复制后 anotherAC 的 On 开头的方法返回的函数只能取消注册 anotherAC 里的事件处理函数。
 func (ac *AcFunLive) CopyEventHandlers(anotherAC *AcFunLive) {
 	anotherAC.handlerMap.RLock()
 	defer anotherAC.handlerMap.RUnlock()
 	ac.handlerMap.Lock()
 	defer ac.handlerMap.Unlock()
 	for k, v := range anotherAC.handlerMap.listMap {
-		ac.handlerMap.listMap[k] = v
+		ac.handlerMap.listMap[k] = slices.Clone(v)
 	}
 }
 
 // StartDanmu 获取弹幕，ctx 用来结束弹幕的获取，event 为 true 时采用事件响应模式。
-// event 为 false 时最好调用 GetDanmu() 或 WriteASS() 以清空弹幕队列。
+// event 为 false 时最好调用 GetDanmu() 或 WriteASS() 以清空弹幕队列，队列满时默认丢弃弹幕，见 SetQueue()。
+// 返回的 channel 在弹幕获取结束时会收到一个错误，主播下播时为 ErrLiveClosed，ctx 结束时为 nil，其他原因可以用 errors.Is 判断。
 // 一个 AcFunLive 只能同时调用 StartDanmu() 一次。
 func (ac *AcFunLive) StartDanmu(ctx context.Context, event bool) <-chan error {
 	ch := make(chan error, 1)
 	if ac.t.liverUID <= 0 {
 		err := fmt.Errorf("主播 uid 不能小于 1")
-		log.Println(err)
+		ac.t.logger().Error(err.Error())
 		ch <- err
 		return ch
 	}
 	if !event {
-		ac.q = queue.New(queueLen)
+		ac.q = newDanmuQueue(ac.queueSize, ac.queuePolicy, ctx.Done(), &ac.stats.danmuDropped)
 	}
 	go ac.clientStart(ctx, event, ch)
 	return ch
 }
 
-// GetDanmu 返回弹幕数据 danmu，danmu 为 nil 时说明弹幕获取结束（出现错误或者主播下播），需要先调用 StartDanmu(ctx, false)。
+// GetDanmu 返回队列里所有的弹幕数据 danmu，队列为空时等待，danmu 为 nil 时说明弹幕获取结束（出现错误或者主播下播），需要先调用 StartDanmu(ctx, false)。
+// 设置 SetQueueAllEvents(true) 后 danmu 里还会有包装其他事件的 *EventMessage。
 // 一个 AcFunLive 只能同时调用 GetDanmu() 一次。
 func (ac *AcFunLive) GetDanmu() (danmu []DanmuMessage) {
 	if ac.q == nil {
-		log.Println("需要先调用 StartDanmu()，event 不能为 true")
+		ac.t.logger().Error("需要先调用 StartDanmu()，event 不能为 true")
 		return nil
 	}
 	if ac.t.liverUID <= 0 {
-		log.Println("主播 uid 不能小于 1")
-		return nil
-	}
-	if (*queue.Queue)(ac.q).Disposed() {
+		ac.t.logger().Error("主播 uid 不能小于 1")
 		return nil
 	}
-	ds, err := ac.q.Get(queueLen)
-	if err != nil {
-		return nil
-	}
-
-	danmu = make([]DanmuMessage, len(ds))
-	for i, d := range ds {
-		danmu[i] = d.(DanmuMessage)
-	}
 
-	return danmu
+	return ac.q.get()
 }
 
 // GetLiveInfo 返回直播间的状态信息，需要先调用 StartDanmu(ctx, false)
@@ -665,6 +1242,11 @@ func (ac *AcFunLive) GetLiveInfo() *LiveInfo {
 	info.TopUsers = append([]TopUser{}, ac.info.TopUsers...)
 	info.RecentComment = append([]Comment{}, ac.info.RecentComment...)
 	info.RedpackList = append([]Redpack{}, ac.info.RedpackList...)
+	info.PKStatistic = ac.info.PKStatistic.copy()
+	info.Widgets = append([]Widget{}, ac.info.Widgets...)
+	info.WishSheet.Wishes = append([]Wish{}, ac.info.WishSheet.Wishes...)
+	info.ApplyUserIDs = append([]int64{}, ac.info.ApplyUserIDs...)
+	info.Features = append([]LiveFeature{}, ac.info.Features...)
 	return &info
 }
 
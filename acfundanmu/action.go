@@ -1,6 +1,7 @@
 package acfundanmu
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -8,21 +9,21 @@ import (
 )
 
 // 房管踢人
-func (t *token) managerKick(liveID string, kickedUID int64) (e error) {
+func (t *token) managerKick(ctx context.Context, liveID string, kickedUID int64) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("managerKick() error: %v", err)
+			e = fmt.Errorf("managerKick() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("房管踢人需要登陆房管的 AcFun 帐号"))
+		panic(errNeedLogin("房管踢人需要登陆房管的 AcFun 帐号"))
 	}
 
 	form := t.defaultForm(liveID)
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("kickedUserId", strconv.FormatInt(kickedUID, 10))
-	body, err := t.fetchKuaiShouAPI(managerKickURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, managerKickURL, form, false, false)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -30,28 +31,28 @@ func (t *token) managerKick(liveID string, kickedUID int64) (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 || !v.GetBool("data", "kickSucc") {
-		panic(fmt.Errorf("房管踢人失败，响应为 %s", string(body)))
+		panic(newAPIError("房管踢人失败", v, body))
 	}
 
 	return nil
 }
 
 // 主播踢人
-func (t *token) authorKick(liveID string, kickedUID int64) (e error) {
+func (t *token) authorKick(ctx context.Context, liveID string, kickedUID int64) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("authorKick() error: %v", err)
+			e = fmt.Errorf("authorKick() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("主播踢人需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("主播踢人需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := t.defaultForm(liveID)
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("kickedUserId", strconv.FormatInt(kickedUID, 10))
-	body, err := t.fetchKuaiShouAPI(authorKickURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, authorKickURL, form, false, false)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -59,29 +60,29 @@ func (t *token) authorKick(liveID string, kickedUID int64) (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 || !v.GetBool("data", "kickSucc") {
-		panic(fmt.Errorf("主播踢人失败，响应为 %s", string(body)))
+		panic(newAPIError("主播踢人失败", v, body))
 	}
 
 	return nil
 }
 
 // 主播添加房管
-func (t *token) addManager(managerUID int64) (e error) {
+func (t *token) addManager(ctx context.Context, managerUID int64) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("addManager() error: %v", err)
+			e = fmt.Errorf("addManager() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("主播添加房管需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("主播添加房管需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("visitorId", strconv.FormatInt(t.UserID, 10))
 	form.Set("managerUserId", strconv.FormatInt(managerUID, 10))
-	body, err := t.fetchKuaiShouAPI(addManagerURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, addManagerURL, form, false, false)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -89,29 +90,29 @@ func (t *token) addManager(managerUID int64) (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("主播添加房管失败，响应为 %s", string(body)))
+		panic(newAPIError("主播添加房管失败", v, body))
 	}
 
 	return nil
 }
 
 // 主播删除房管
-func (t *token) deleteManager(managerUID int64) (e error) {
+func (t *token) deleteManager(ctx context.Context, managerUID int64) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("deleteManager() error: %v", err)
+			e = fmt.Errorf("deleteManager() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("主播删除房管需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("主播删除房管需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("visitorId", strconv.FormatInt(t.UserID, 10))
 	form.Set("managerUserId", strconv.FormatInt(managerUID, 10))
-	body, err := t.fetchKuaiShouAPI(deleteManagerURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, deleteManagerURL, form, false, false)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -119,30 +120,33 @@ func (t *token) deleteManager(managerUID int64) (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("主播删除房管失败，响应为 %s", string(body)))
+		panic(newAPIError("主播删除房管失败", v, body))
 	}
 
 	return nil
 }
 
 // 佩戴守护徽章
-func (t *token) wearMedal(uid int64) (e error) {
+func (t *token) wearMedal(ctx context.Context, uid int64) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("wearMedal() error: %v", err)
+			e = fmt.Errorf("wearMedal() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("佩戴守护徽章需要登陆 AcFun 帐号"))
+		panic(errNeedLogin("佩戴守护徽章需要登陆 AcFun 帐号"))
 	}
 
 	client := &httpClient{
-		url:      fmt.Sprintf(wearMedalURL, uid),
-		method:   "GET",
-		cookies:  t.Cookies,
-		referer:  t.livePage,
-		deviceID: t.DeviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       fmt.Sprintf(wearMedalURL, uid),
+		method:    "GET",
+		cookies:   t.Cookies,
+		referer:   t.livePage,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -152,30 +156,33 @@ func (t *token) wearMedal(uid int64) (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("佩戴守护徽章失败，响应为 %s", string(body)))
+		panic(newAPIError("佩戴守护徽章失败", v, body))
 	}
 
 	return nil
 }
 
 // 取消佩戴守护徽章
-func (t *token) cancelWearMedal(liverUID int64) (e error) {
+func (t *token) cancelWearMedal(ctx context.Context, liverUID int64) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("cancelWearMedal() error: %v", err)
+			e = fmt.Errorf("cancelWearMedal() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("取消佩戴守护徽章需要登陆 AcFun 帐号"))
+		panic(errNeedLogin("取消佩戴守护徽章需要登陆 AcFun 帐号"))
 	}
 
 	client := &httpClient{
-		url:      fmt.Sprintf(cancelWearMedalURL, liverUID),
-		method:   "GET",
-		cookies:  t.Cookies,
-		referer:  t.livePage,
-		deviceID: t.DeviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       fmt.Sprintf(cancelWearMedalURL, liverUID),
+		method:    "GET",
+		cookies:   t.Cookies,
+		referer:   t.livePage,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -185,7 +192,7 @@ func (t *token) cancelWearMedal(liverUID int64) (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("取消佩戴守护徽章失败，响应为 %s", string(body)))
+		panic(newAPIError("取消佩戴守护徽章失败", v, body))
 	}
 
 	return nil
@@ -193,40 +200,75 @@ func (t *token) cancelWearMedal(liverUID int64) (e error) {
 
 // ManagerKick 房管踢人，需要登陆 AcFun 帐号，需要设置主播 uid
 func (ac *AcFunLive) ManagerKick(liveID string, kickedUID int64) error {
-	return ac.t.managerKick(liveID, kickedUID)
+	return ac.ManagerKickCtx(context.Background(), liveID, kickedUID)
+}
+
+// ManagerKickCtx 和 ManagerKick() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) ManagerKickCtx(ctx context.Context, liveID string, kickedUID int64) error {
+	return ac.t.managerKick(ctx, liveID, kickedUID)
 }
 
 // AuthorKick 主播踢人，需要登陆 AcFun 帐号，需要设置主播 uid
 func (ac *AcFunLive) AuthorKick(liveID string, kickedUID int64) error {
-	return ac.t.authorKick(liveID, kickedUID)
+	return ac.AuthorKickCtx(context.Background(), liveID, kickedUID)
+}
+
+// AuthorKickCtx 和 AuthorKick() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) AuthorKickCtx(ctx context.Context, liveID string, kickedUID int64) error {
+	return ac.t.authorKick(ctx, liveID, kickedUID)
 }
 
 // AddManager 主播添加房管，需要登陆 AcFun 帐号
 func (ac *AcFunLive) AddManager(managerUID int64) error {
-	return ac.t.addManager(managerUID)
+	return ac.AddManagerCtx(context.Background(), managerUID)
+}
+
+// AddManagerCtx 和 AddManager() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) AddManagerCtx(ctx context.Context, managerUID int64) error {
+	return ac.t.addManager(ctx, managerUID)
 }
 
 // DeleteManager 主播删除房管，需要登陆 AcFun 帐号
 func (ac *AcFunLive) DeleteManager(managerUID int64) error {
-	return ac.t.deleteManager(managerUID)
+	return ac.DeleteManagerCtx(context.Background(), managerUID)
+}
+
+// DeleteManagerCtx 和 DeleteManager() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) DeleteManagerCtx(ctx context.Context, managerUID int64) error {
+	return ac.t.deleteManager(ctx, managerUID)
 }
 
 // WearMedal 佩戴 uid 指定的主播的守护徽章，需要登陆 AcFun 帐号，如果登陆帐号没有 uid 指定的主播的守护徽章则会取消佩戴任何徽章
 func (ac *AcFunLive) WearMedal(uid int64) error {
-	return ac.t.wearMedal(uid)
+	return ac.WearMedalCtx(context.Background(), uid)
+}
+
+// WearMedalCtx 和 WearMedal() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) WearMedalCtx(ctx context.Context, uid int64) error {
+	return ac.t.wearMedal(ctx, uid)
 }
 
 // CancelWearMedalWithLiverUID 取消佩戴守护徽章，需要登陆 AcFun 帐号，liverUID 必须是登陆帐号正在佩戴的守护徽章的主播 uid
 func (ac *AcFunLive) CancelWearMedalWithLiverUID(liverUID int64) error {
-	return ac.t.cancelWearMedal(liverUID)
+	return ac.CancelWearMedalWithLiverUIDCtx(context.Background(), liverUID)
+}
+
+// CancelWearMedalWithLiverUIDCtx 和 CancelWearMedalWithLiverUID() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) CancelWearMedalWithLiverUIDCtx(ctx context.Context, liverUID int64) error {
+	return ac.t.cancelWearMedal(ctx, liverUID)
 }
 
 // CancelWearMedal 取消佩戴守护徽章，需要登陆 AcFun 帐号
 func (ac *AcFunLive) CancelWearMedal() error {
-	medal, err := getUserMedal(ac.t.UserID, ac.t.DeviceID)
+	return ac.CancelWearMedalCtx(context.Background())
+}
+
+// CancelWearMedalCtx 和 CancelWearMedal() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) CancelWearMedalCtx(ctx context.Context) error {
+	medal, err := ac.t.getUserMedal(ctx, ac.t.UserID)
 	if err != nil {
 		return err
 	}
 
-	return ac.t.cancelWearMedal(medal.UperID)
+	return ac.t.cancelWearMedal(ctx, medal.UperID)
 }
@@ -3,13 +3,10 @@ package acfundanmu
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 	"time"
 	"unicode/utf8"
-
-	"github.com/Workiva/go-datastructures/queue"
 )
 
 // ass 文件的 Script Info
@@ -91,20 +88,19 @@ func convert(name string) string {
 func (ac *AcFunLive) WriteASS(ctx context.Context, s SubConfig, file string, newFile bool) {
 	defer func() {
 		if err := recover(); err != nil {
-			log.Printf("Recovering from panic in WriteASS(), the error is: %v", err)
-			log.Println("停止写入 ass 字幕")
+			ac.t.logger().Error("Recovering from panic in WriteASS()，停止写入 ass 字幕", "error", err)
 		}
 	}()
 
 	if ac.q == nil {
-		log.Println("需要先调用 StartDanmu()，event 不能为 true")
+		ac.t.logger().Error("需要先调用 StartDanmu()，event 不能为 true")
 		return
 	}
 	if ac.t.liverUID == 0 {
-		log.Println("主播 uid 不能为 0")
+		ac.t.logger().Error("主播 uid 不能为 0")
 		return
 	}
-	if (*queue.Queue)(ac.q).Disposed() {
+	if ac.q.finished() {
 		return
 	}
 
@@ -0,0 +1,125 @@
+package acfundanmu
+
+import (
+	"testing"
+
+	"github.com/orzogc/acfundanmu/acproto"
+)
+
+// 返回回放用的 *AcFunLive，抓取文件只有一条空的弹幕数据
+func benchAcFunLive(b *testing.B) *AcFunLive {
+	b.Helper()
+	ac := replayAcFunLive(b, []*acproto.DownstreamPayload{{}})
+	ac.t.gifts[1] = GiftDetail{GiftID: 1, GiftName: "香蕉", Price: 1, PayWalletType: 1}
+
+	return ac
+}
+
+// 生成有评论、点赞、进入直播间和礼物的 ZtLiveScActionSignal
+func benchActionSignal(b *testing.B) []byte {
+	b.Helper()
+	user := &acproto.ZtLiveUserInfo{UserId: 1000, Nickname: "用户"}
+	signal := &acproto.ZtLiveScActionSignal{
+		Item: []*acproto.ZtLiveActionSignalItem{
+			{
+				SignalType: "CommonActionSignalComment",
+				Payload: [][]byte{
+					marshal(b, &acproto.CommonActionSignalComment{Content: "弹幕1", SendTimeMs: 1, UserInfo: user}),
+					marshal(b, &acproto.CommonActionSignalComment{Content: "弹幕2", SendTimeMs: 2, UserInfo: user}),
+				},
+			},
+			{
+				SignalType: "CommonActionSignalLike",
+				Payload:    [][]byte{marshal(b, &acproto.CommonActionSignalLike{SendTimeMs: 3, UserInfo: user})},
+			},
+			{
+				SignalType: "CommonActionSignalUserEnterRoom",
+				Payload:    [][]byte{marshal(b, &acproto.CommonActionSignalUserEnterRoom{SendTimeMs: 4, UserInfo: user})},
+			},
+			{
+				SignalType: "CommonActionSignalGift",
+				Payload: [][]byte{marshal(b, &acproto.CommonActionSignalGift{
+					SendTimeMs: 5, GiftId: 1, BatchSize: 1, ComboCount: 1, ComboKey: "combo", UserInfo: user,
+				})},
+			},
+		},
+	}
+
+	return marshal(b, signal)
+}
+
+// 生成加密后的弹幕数据帧
+func benchFrame(b *testing.B, ac *AcFunLive) []byte {
+	b.Helper()
+	stream := &acproto.DownstreamPayload{
+		Command: "Push.ZtLiveInteractive.Message",
+		SeqId:   1,
+		PayloadData: marshal(b, &acproto.ZtLiveScMessage{
+			MessageType: "ZtLiveScActionSignal",
+			Payload:     benchActionSignal(b),
+		}),
+	}
+	body := marshal(b, stream)
+	header := &acproto.PacketHeader{
+		AppId:             13,
+		DecodedPayloadLen: uint32(len(body)),
+		EncryptionMode:    acproto.PacketHeader_kEncryptionSessionKey,
+		SeqId:             1,
+	}
+
+	return ac.t.encode(header, body)
+}
+
+func BenchmarkDecode(b *testing.B) {
+	ac := benchAcFunLive(b)
+	frame := benchFrame(b, ac)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(frame)))
+	b.ResetTimer()
+	for range b.N {
+		stream, err := ac.t.decode(frame)
+		if err != nil {
+			b.Fatal(err)
+		}
+		downstreamPool.put(stream)
+	}
+}
+
+func BenchmarkGetFrames(b *testing.B) {
+	ac := benchAcFunLive(b)
+	frame := benchFrame(b, ac)
+	// 多个完整的帧加上半个帧
+	var data []byte
+	for range 8 {
+		data = append(data, frame...)
+	}
+	data = append(data, frame[:len(frame)/2]...)
+
+	var frames [][]byte
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for range b.N {
+		var err error
+		frames, _, err = getFrames(frames[:0], data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(frames) != 8 {
+			b.Fatalf("got %d frames, want 8", len(frames))
+		}
+	}
+}
+
+func BenchmarkHandleActionSignal(b *testing.B) {
+	ac := benchAcFunLive(b)
+	payload := benchActionSignal(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	for range b.N {
+		ac.handleActionSignal(&payload, true)
+	}
+}
@@ -0,0 +1,302 @@
+package acfundanmu
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/orzogc/acfundanmu/acproto"
+	"go.uber.org/atomic"
+	"google.golang.org/protobuf/proto"
+)
+
+// 抓取文件的开头，后面接着主播 uid（varint）
+const captureMagic = "ACDANMU1"
+
+// 抓取的弹幕数据的写入
+type captureWriter struct {
+	sync.Mutex
+	w             *bufio.Writer
+	headerWritten bool
+	err           error
+}
+
+// 写入一条弹幕数据，格式为接收时间（以毫秒为单位的 Unix 时间，varint）、数据长度（uvarint）和 DownstreamPayload。
+// 第一次写入失败时返回错误，之后不再写入。
+func (c *captureWriter) write(liverUID int64, stream *acproto.DownstreamPayload) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.err != nil {
+		return nil
+	}
+
+	data, err := proto.Marshal(stream)
+	if err == nil {
+		buf := make([]byte, 0, len(captureMagic)+3*binary.MaxVarintLen64+len(data))
+		if !c.headerWritten {
+			buf = append(buf, captureMagic...)
+			buf = binary.AppendVarint(buf, liverUID)
+			c.headerWritten = true
+		}
+		buf = binary.AppendVarint(buf, time.Now().UnixMilli())
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		buf = append(buf, data...)
+		if _, err = c.w.Write(buf); err == nil {
+			err = c.w.Flush()
+		}
+	}
+	c.err = err
+
+	return err
+}
+
+// 抓取解码后的弹幕数据
+func (ac *AcFunLive) captureStream(stream *acproto.DownstreamPayload) {
+	if ac.capture != nil {
+		if err := ac.capture.write(ac.t.liverUID, stream); err != nil {
+			ac.t.logger().Error("写入抓取的弹幕数据出现错误，停止抓取", "error", err)
+		}
+	}
+}
+
+// 读取抓取文件的开头，返回主播 uid
+func readCaptureHeader(r *bufio.Reader) (int64, error) {
+	magic := make([]byte, len(captureMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return 0, fmt.Errorf("读取抓取文件的开头出现错误：%w", err)
+	}
+	if string(magic) != captureMagic {
+		return 0, fmt.Errorf("不是弹幕数据的抓取文件")
+	}
+	uid, err := binary.ReadVarint(r)
+	if err != nil {
+		return 0, fmt.Errorf("读取抓取文件的主播 uid 出现错误：%w", err)
+	}
+
+	return uid, nil
+}
+
+// ReplayDanmuClient 回放 SetCapture() 抓取的弹幕数据的弹幕客户端，需要配合 NewReplayAcFunLive() 使用。
+// 数据按 TCP 连接的格式返回，写入的数据会被丢弃，回放结束时 Read() 返回 io.EOF。
+type ReplayDanmuClient struct {
+	filename string
+	speed    float64
+
+	mu     sync.Mutex
+	file   *os.File
+	reader *bufio.Reader
+	done   chan struct{}
+	remain []byte // 还没读取的弹幕数据
+	start  time.Time
+	first  int64 // 第一条弹幕数据的接收时间
+}
+
+// NewReplayDanmuClient 返回回放抓取文件 filename 的弹幕客户端。
+// speed 为回放速度，1 为按原来的时间间隔回放，2 为两倍速，小于等于 0 时不等待直接回放。
+func NewReplayDanmuClient(filename string, speed float64) *ReplayDanmuClient {
+	return &ReplayDanmuClient{
+		filename: filename,
+		speed:    speed,
+	}
+}
+
+// NewDanmuClient 返回新的 ReplayDanmuClient，回放同一个抓取文件
+func (client *ReplayDanmuClient) NewDanmuClient() DanmuClient {
+	return NewReplayDanmuClient(client.filename, client.speed)
+}
+
+// Type 返回弹幕客户端类型 TCPDanmuClientType
+func (client *ReplayDanmuClient) Type() DanmuClientType {
+	return TCPDanmuClientType
+}
+
+// Dial 打开抓取文件，忽略 address
+func (client *ReplayDanmuClient) Dial(address string) error {
+	file, err := os.Open(client.filename)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(file)
+	if _, err = readCaptureHeader(reader); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.file = file
+	client.reader = reader
+	client.done = make(chan struct{})
+	client.remain = nil
+	client.start = time.Now()
+	client.first = 0
+
+	return nil
+}
+
+// Read 读取下一条弹幕数据，会按回放速度等待
+func (client *ReplayDanmuClient) Read(p []byte) (n int, err error) {
+	client.mu.Lock()
+	reader, done := client.reader, client.done
+	if reader == nil {
+		client.mu.Unlock()
+		return 0, fmt.Errorf("请先调用 Dail() 连接服务器")
+	}
+	if len(client.remain) > 0 {
+		n = copy(p, client.remain)
+		client.remain = client.remain[n:]
+		client.mu.Unlock()
+		return n, nil
+	}
+	client.mu.Unlock()
+
+	frame, err := client.next(reader, done)
+	if err != nil {
+		select {
+		case <-done:
+			return 0, net.ErrClosed
+		default:
+			return 0, err
+		}
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	n = copy(p, frame)
+	client.remain = frame[n:]
+
+	return n, nil
+}
+
+// 读取下一条弹幕数据并编码成 TCP 连接的格式
+func (client *ReplayDanmuClient) next(reader *bufio.Reader, done <-chan struct{}) ([]byte, error) {
+	recvTime, err := binary.ReadVarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	payload := make([]byte, length)
+	if _, err = io.ReadFull(reader, payload); err != nil {
+		return nil, err
+	}
+
+	if client.speed > 0 {
+		if client.first == 0 {
+			client.first = recvTime
+		}
+		offset := time.Duration(float64(time.Duration(recvTime-client.first)*time.Millisecond) / client.speed)
+		if d := time.Until(client.start.Add(offset)); d > 0 {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-done:
+				return nil, net.ErrClosed
+			case <-timer.C:
+			}
+		}
+	}
+
+	stream := &acproto.DownstreamPayload{}
+	if err = proto.Unmarshal(payload, stream); err != nil {
+		return nil, fmt.Errorf("解码抓取的弹幕数据出现错误：%w", err)
+	}
+	header := &acproto.PacketHeader{
+		DecodedPayloadLen: uint32(len(payload)),
+		EncryptionMode:    acproto.PacketHeader_kEncryptionNone,
+		SeqId:             stream.SeqId,
+	}
+	headerBytes, err := proto.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 12+len(headerBytes)+len(payload)))
+	_ = binary.Write(buf, binary.BigEndian, uint32(0xABCD0001))
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(headerBytes)))
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(payload)))
+	_, _ = buf.Write(headerBytes)
+	_, _ = buf.Write(payload)
+
+	return buf.Bytes(), nil
+}
+
+// Write 丢弃写入的数据
+func (client *ReplayDanmuClient) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+// Close 关闭抓取文件
+func (client *ReplayDanmuClient) Close(message string) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.file == nil {
+		return nil
+	}
+	close(client.done)
+	err := client.file.Close()
+	client.file = nil
+	if errors.Is(err, os.ErrClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// NewReplayAcFunLive 返回回放抓取文件的 *AcFunLive，不需要联网，主播 uid 从抓取文件读取。
+// 回放时调用 StartDanmu() 获取弹幕，弹幕数据会经过和直播时一样的处理流程，不支持自动重连和调用需要联网的方法。
+// 回放时不会获取礼物列表，礼物的 GiftDetail 只有礼物 ID，GiftName 为“未知礼物”。
+func NewReplayAcFunLive(client *ReplayDanmuClient, options ...Option) (ac *AcFunLive, err error) {
+	if client == nil {
+		return nil, fmt.Errorf("NewReplayAcFunLive() error: client 不能为 nil")
+	}
+	file, err := os.Open(client.filename)
+	if err != nil {
+		return nil, fmt.Errorf("NewReplayAcFunLive() error: %w", err)
+	}
+	defer file.Close()
+	uid, err := readCaptureHeader(bufio.NewReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("NewReplayAcFunLive() error: %w", err)
+	}
+
+	ac = new(AcFunLive)
+	ac.info = new(liveInfo)
+	ac.t = new(token)
+	ac.handlerMap = new(handlerMap)
+	ac.handlerMap.listMap = make(map[EventType][]handlerEntry)
+	ac.queueSize = defaultQueueSize
+	ac.queuePolicy = OverflowDropLowPriority
+
+	for _, option := range options {
+		option(ac)
+	}
+
+	ac.danmuClient = client
+	ac.replay = true
+	ac.reconnect = nil
+	ac.t.liverUID = uid
+	ac.t.livePage = fmt.Sprintf(liveURL, uid)
+	// 发送的数据会被丢弃，只需要能正常加密
+	ac.t.SecurityKey = base64.StdEncoding.EncodeToString(make([]byte, 16))
+	ac.t.sessionKey = make([]byte, 16)
+	ac.t.tickets = []string{""}
+	ac.t.seqID = atomic.NewInt64(1)
+	ac.t.headerSeqID = atomic.NewInt64(1)
+	ac.t.ticketIndex = atomic.NewUint32(0)
+	ac.t.err = atomic.NewError(nil)
+	ac.t.closed = atomic.NewBool(false)
+	ac.t.gifts = make(map[int64]GiftDetail)
+
+	return ac, nil
+}
@@ -0,0 +1,183 @@
+package acfundanmu
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/orzogc/acfundanmu/acproto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func marshal(tb testing.TB, m proto.Message) []byte {
+	tb.Helper()
+	data, err := proto.Marshal(m)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return data
+}
+
+// 生成 Push.ZtLiveInteractive.Message 的 DownstreamPayload
+func scMessage(tb testing.TB, messageType string, payload []byte) *acproto.DownstreamPayload {
+	return &acproto.DownstreamPayload{
+		Command:     "Push.ZtLiveInteractive.Message",
+		PayloadData: marshal(tb, &acproto.ZtLiveScMessage{MessageType: messageType, Payload: payload}),
+	}
+}
+
+// 生成只有一个 signal 的 ZtLiveScActionSignal
+func actionMessage(tb testing.TB, signalType string, payloads ...[]byte) *acproto.DownstreamPayload {
+	return scMessage(tb, "ZtLiveScActionSignal", marshal(tb, &acproto.ZtLiveScActionSignal{
+		Item: []*acproto.ZtLiveActionSignalItem{{SignalType: signalType, Payload: payloads}},
+	}))
+}
+
+func commentMessage(tb testing.TB, uid, sendTime int64, content string) *acproto.DownstreamPayload {
+	return actionMessage(tb, "CommonActionSignalComment", marshal(tb, &acproto.CommonActionSignalComment{
+		Content:    content,
+		SendTimeMs: sendTime,
+		UserInfo:   &acproto.ZtLiveUserInfo{UserId: uid, Nickname: "用户"},
+	}))
+}
+
+func giftMessage(tb testing.TB, uid, sendTime, giftID int64, combo int32, comboKey string) *acproto.DownstreamPayload {
+	return actionMessage(tb, "CommonActionSignalGift", marshal(tb, &acproto.CommonActionSignalGift{
+		SendTimeMs:            sendTime,
+		GiftId:                giftID,
+		BatchSize:             1,
+		ComboCount:            combo,
+		ComboKey:              comboKey,
+		SlotDisplayDurationMs: 50,
+		UserInfo:              &acproto.ZtLiveUserInfo{UserId: uid, Nickname: "用户"},
+	}))
+}
+
+func liveClosedMessage(tb testing.TB) *acproto.DownstreamPayload {
+	return scMessage(tb, "ZtLiveScStatusChanged", marshal(tb, &acproto.ZtLiveScStatusChanged{
+		Type: acproto.ZtLiveScStatusChanged_LIVE_CLOSED,
+	}))
+}
+
+// 将 streams 写入抓取文件，返回文件路径
+func writeCapture(tb testing.TB, liverUID int64, streams ...*acproto.DownstreamPayload) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "capture")
+	f, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+	c := &captureWriter{w: bufio.NewWriter(f)}
+	for _, stream := range streams {
+		if err = c.write(liverUID, stream); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	return path
+}
+
+// 返回回放 streams 的 *AcFunLive
+func replayAcFunLive(tb testing.TB, streams []*acproto.DownstreamPayload, options ...Option) *AcFunLive {
+	tb.Helper()
+	options = append([]Option{SetLogger(DiscardLogHandler)}, options...)
+	ac, err := NewReplayAcFunLive(NewReplayDanmuClient(writeCapture(tb, 1, streams...), 0), options...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return ac
+}
+
+func TestReadCaptureHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		uid     int64
+		wantErr bool
+	}{
+		{"正常", captureMagic + "\x02", 1, false},
+		{"空文件", "", 0, true},
+		{"错误的开头", "ACDANMU0\x02", 0, true},
+		{"没有主播 uid", captureMagic, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uid, err := readCaptureHeader(bufio.NewReader(strings.NewReader(tt.data)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readCaptureHeader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if uid != tt.uid {
+				t.Errorf("readCaptureHeader() uid = %d, want %d", uid, tt.uid)
+			}
+		})
+	}
+}
+
+func TestCaptureReplay(t *testing.T) {
+	streams := []*acproto.DownstreamPayload{
+		commentMessage(t, 10, 1000, "弹幕1"),
+		giftMessage(t, 11, 1001, 12345, 1, "combo"),
+		commentMessage(t, 12, 1002, "弹幕2"),
+		liveClosedMessage(t),
+	}
+	path := writeCapture(t, 100, streams...)
+
+	ac, err := NewReplayAcFunLive(NewReplayDanmuClient(path, 0), SetLogger(DiscardLogHandler), SetEventDispatchMode(DispatchOrdered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uid := ac.GetLiverUID(); uid != 100 {
+		t.Fatalf("GetLiverUID() = %d, want 100", uid)
+	}
+
+	var comments []string
+	var gifts []GiftDetail
+	var stopErr error
+	for e := range ac.Events(context.Background()) {
+		switch d := e.Data.(type) {
+		case *Comment:
+			comments = append(comments, d.Content)
+		case *Gift:
+			gifts = append(gifts, d.GiftDetail)
+		}
+		if e.Type == EventDanmuStop {
+			stopErr, _ = e.Data.(error)
+		}
+	}
+
+	if want := []string{"弹幕1", "弹幕2"}; strings.Join(comments, ",") != strings.Join(want, ",") {
+		t.Errorf("comments = %v, want %v", comments, want)
+	}
+	// 回放时不联网获取礼物列表
+	if len(gifts) != 1 || gifts[0].GiftID != 12345 || gifts[0].GiftName != "未知礼物" {
+		t.Errorf("gifts = %+v, want one unknown gift 12345", gifts)
+	}
+	if !errors.Is(stopErr, ErrLiveClosed) {
+		t.Errorf("EventDanmuStop error = %v, want ErrLiveClosed", stopErr)
+	}
+}
+
+func TestCaptureWriterStopsAfterError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &captureWriter{w: bufio.NewWriterSize(f, 16)}
+	_ = f.Close()
+
+	if err = c.write(1, commentMessage(t, 1, 1, "弹幕")); err == nil {
+		t.Fatal("write() to closed file returned nil error")
+	}
+	if err = c.write(1, commentMessage(t, 1, 2, "弹幕")); err != nil {
+		t.Errorf("write() after error = %v, want nil", err)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
+	"net/url"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
@@ -73,12 +75,13 @@ type DanmuClient interface {
 
 // WebSocketDanmuClient 使用 WebSocket 连接的弹幕客户端
 type WebSocketDanmuClient struct {
-	conn *fastws.Conn
+	conn  *fastws.Conn
+	proxy *url.URL // 代理，为 nil 时使用全局代理
 }
 
 // NewDanmuClient 返回新的 WebSocketDanmuClient
 func (client *WebSocketDanmuClient) NewDanmuClient() DanmuClient {
-	return &WebSocketDanmuClient{}
+	return &WebSocketDanmuClient{proxy: client.proxy}
 }
 
 // Type 返回弹幕客户端类型 WebSocketDanmuClientType
@@ -88,7 +91,7 @@ func (client *WebSocketDanmuClient) Type() DanmuClientType {
 
 // Dial 连接弹幕服务器，address 是地址
 func (client *WebSocketDanmuClient) Dial(address string) error {
-	conn, err := fastws.Dial(address)
+	conn, err := dialWebSocket(client.proxy, address)
 	if err != nil {
 		client.conn = nil
 		return err
@@ -131,12 +134,13 @@ func (client *WebSocketDanmuClient) Close(message string) error {
 
 // TCPDanmuClient 使用 TCP 连接的弹幕客户端
 type TCPDanmuClient struct {
-	conn net.Conn
+	conn  net.Conn
+	proxy *url.URL // 代理，为 nil 时使用全局代理
 }
 
 // NewDanmuClient 返回新的 TCPDanmuClient
 func (client *TCPDanmuClient) NewDanmuClient() DanmuClient {
-	return &TCPDanmuClient{}
+	return &TCPDanmuClient{proxy: client.proxy}
 }
 
 // Type 返回弹幕客户端类型 TCPDanmuClientType
@@ -146,7 +150,7 @@ func (client *TCPDanmuClient) Type() DanmuClientType {
 
 // Dial 连接弹幕服务器，address 是地址
 func (client *TCPDanmuClient) Dial(address string) error {
-	conn, err := net.DialTimeout("tcp", address, timeout)
+	conn, err := dialTCP(client.proxy, address)
 	if err != nil {
 		client.conn = nil
 		return err
@@ -189,6 +193,21 @@ func (client *TCPDanmuClient) Close(message string) error {
 	}
 }
 
+// 可以设置代理的弹幕客户端
+type proxySetter interface {
+	setProxy(proxy *url.URL)
+}
+
+// 设置代理
+func (client *WebSocketDanmuClient) setProxy(proxy *url.URL) {
+	client.proxy = proxy
+}
+
+// 设置代理
+func (client *TCPDanmuClient) setProxy(proxy *url.URL) {
+	client.proxy = proxy
+}
+
 // 弹幕消息
 type message struct {
 	bytes *[]byte
@@ -200,14 +219,25 @@ func (m *message) data() []byte {
 	return (*m.bytes)[:m.len]
 }
 
+// 启动当前连接的心跳 goroutine，ctx 结束时退出，clientConnect() 返回前会等待退出
+func (ac *AcFunLive) startHeartbeat(ctx context.Context, interval int64) {
+	ac.heartbeat.Store(interval)
+	ac.connGoroutines.Add(1)
+	go func() {
+		defer ac.connGoroutines.Done()
+		ac.clientHeartbeat(ctx, interval)
+	}()
+}
+
 // 定时发送 heartbeat 和 keepalive 数据
 func (ac *AcFunLive) clientHeartbeat(ctx context.Context, interval int64) {
 	defer func() {
 		if err := recover(); err != nil {
-			log.Printf("Recovering from panic in clientHeartbeat(), the error is: %v", err)
-			// 重新启动 clientHeartbeat()
-			time.Sleep(2 * time.Second)
-			ac.clientHeartbeat(ctx, interval)
+			ac.t.logger().Error("Recovering from panic in clientHeartbeat()", "error", err)
+			// 重新启动 clientHeartbeat()，连接已经结束时直接退出
+			if sleepContext(ctx, 2*time.Second) {
+				ac.clientHeartbeat(ctx, interval)
+			}
 		}
 	}()
 
@@ -220,57 +250,313 @@ func (ac *AcFunLive) clientHeartbeat(ctx context.Context, interval int64) {
 		case <-ticker.C:
 			_, err := ac.danmuClient.Write(ac.t.heartbeat())
 			checkErr(err)
+			_ = ac.stats.heartbeatsSent.Inc()
 			if ac.t.heartbeatSeqID%5 == 4 {
 				_, err = ac.danmuClient.Write(ac.t.keepAlive())
 				checkErr(err)
+				// 顺便测量往返时间
+				ac.stats.pingSending()
+				_, err = ac.danmuClient.Write(ac.t.ping())
+				checkErr(err)
+			}
+		}
+	}
+}
+
+// 连接停滞的看门狗，从连接弹幕服务器开始每秒检查一次，覆盖握手和进入直播间前的阶段，ctx 结束时退出
+func (ac *AcFunLive) stallWatchdog(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if ac.checkStalled() {
+				return
 			}
 		}
 	}
 }
 
+// 检查连接是否停滞，超过 stallLimit 个心跳间隔没有收到心跳回应或其他弹幕数据时关闭连接并返回 true
+func (ac *AcFunLive) checkStalled() bool {
+	silence := time.Duration(time.Now().UnixNano() - ac.lastAlive.Load())
+	if silence <= time.Duration(int64(ac.stallLimit)*ac.heartbeat.Load())*time.Millisecond {
+		return false
+	}
+
+	ac.t.logger().Warn("弹幕连接停滞，关闭连接", "silence", silence, "missedHeartbeats", ac.stallLimit)
+	ac.stalled.Store(true)
+	_ = ac.danmuClient.Close("Stalled")
+
+	return true
+}
+
 // 启动弹幕 client
 func (ac *AcFunLive) clientStart(ctx context.Context, event bool, errCh chan<- error) {
+	// 最先 defer，保证在最后的 EventDanmuStop 事件之后关闭
+	defer ac.closeStream()
 	defer func() {
 		if err := recover(); err != nil {
-			log.Printf("Recovering from panic in clientStart(), the error is:  %v", err)
-			log.Println("停止获取弹幕")
-			errCh <- err.(error)
+			e := recoverErr(err)
+			ac.t.logger().Error("Recovering from panic in clientStart()，停止获取弹幕", "error", e)
+			errCh <- e
 			close(errCh)
-			if event {
-				ac.callEvent(stopDanmu, err.(error))
-			}
+			ac.emitEvent(event, EventDanmuStop, e)
 		}
 	}()
 
 	if !event {
-		defer ac.q.Dispose()
+		defer ac.q.close()
 	}
 
+	ac.attempt.Store(0)
+	for {
+		err := ac.clientConnect(ctx, event)
+		if err == nil {
+			break
+		}
+
+		attempt := int(ac.attempt.Inc())
+		if !ac.canReconnect(ctx, attempt) {
+			if errors.Is(err, ErrLiveClosed) {
+				ac.t.logger().Info("主播已下播，停止获取直播弹幕")
+			} else {
+				ac.t.logger().Error("接收弹幕数据出现错误，停止获取直播弹幕", "error", err)
+			}
+			errCh <- err
+			close(errCh)
+			ac.emitEvent(event, EventDanmuStop, err)
+			return
+		}
+
+		delay := ac.reconnect.delay(attempt)
+		ac.t.logger().Warn("弹幕连接出现错误，准备重连直播间", "error", err, "delay", delay, "attempt", attempt)
+		ac.emitEvent(event, EventReconnecting, &Reconnecting{
+			Attempt: attempt,
+			Delay:   delay,
+			Err:     err,
+		})
+		if !sleepContext(ctx, delay) {
+			break
+		}
+		ac.t.resetConnection()
+		_ = ac.stats.reconnects.Inc()
+		_ = ac.stats.ticketRotations.Inc()
+	}
+
+	errCh <- nil
+	close(errCh)
+	ac.emitEvent(event, EventDanmuStop, nil)
+}
+
+// 判断第 attempt 次重连是否允许
+func (ac *AcFunLive) canReconnect(ctx context.Context, attempt int) bool {
+	// 服务器要求断开的连接不重连
+	if ac.reconnect == nil || ctx.Err() != nil || ac.t.err.Load() != nil {
+		return false
+	}
+
+	return ac.reconnect.MaxAttempts <= 0 || attempt <= ac.reconnect.MaxAttempts
+}
+
+// 等待 d，ctx 结束时返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// 弹幕服务器下发的接入点地址
+type accessPoints struct {
+	sync.Mutex
+	addresses []string
+}
+
+// 返回要尝试连接的弹幕服务器地址，设置的地址（默认为 AcFun 的弹幕服务器）在前，接入点地址在后
+func (ac *AcFunLive) serverAddresses() []string {
+	addresses := append([]string{}, ac.servers...)
+	if len(addresses) == 0 {
+		switch ac.danmuClient.Type() {
+		case WebSocketDanmuClientType:
+			addresses = append(addresses, wsHost)
+		case TCPDanmuClientType:
+			addresses = append(addresses, tcpHost)
+		}
+	}
+
+	ac.accessPoints.Lock()
+	defer ac.accessPoints.Unlock()
+	for _, address := range ac.accessPoints.addresses {
+		if !slices.Contains(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses
+}
+
+// 按顺序连接弹幕服务器，直到有地址连接成功
+func (ac *AcFunLive) dialServer() error {
+	addresses := ac.serverAddresses()
+	if len(addresses) == 0 {
+		return fmt.Errorf("没有可以连接的弹幕服务器地址")
+	}
+
+	errs := make([]error, 0, len(addresses))
+	for _, address := range addresses {
+		err := ac.danmuClient.Dial(address)
+		if err == nil {
+			return nil
+		}
+		ac.t.logger().Warn("连接弹幕服务器失败", "address", address, "error", err)
+		errs = append(errs, fmt.Errorf("%s：%w", address, err))
+	}
+
+	return fmt.Errorf("连接弹幕服务器失败：%w", errors.Join(errs...))
+}
+
+// 更新弹幕服务器下发的接入点地址，clean 为 true 时先清空原有的接入点地址
+func (ac *AcFunLive) updateAccessPoints(config *acproto.AccessPointsConfig, clean bool) {
+	ac.accessPoints.Lock()
+	defer ac.accessPoints.Unlock()
+	if clean {
+		ac.accessPoints.addresses = nil
+	}
+	if config == nil {
+		return
+	}
+
+	var port uint32
+	if len(config.AvailablePorts) > 0 {
+		port = config.AvailablePorts[0]
+	}
+	aps := make([]*acproto.AccessPoint, 0, len(config.OptimalAps)+len(config.BackupAps)+1)
+	aps = append(aps, config.ForceLastConnectedAp)
+	aps = append(aps, config.OptimalAps...)
+	aps = append(aps, config.BackupAps...)
+	for _, ap := range aps {
+		address := accessPointAddress(ac.danmuClient.Type(), ap, port)
+		if address != "" && !slices.Contains(ac.accessPoints.addresses, address) {
+			ac.accessPoints.addresses = append(ac.accessPoints.addresses, address)
+		}
+	}
+}
+
+// 将接入点转换为弹幕客户端可以连接的地址，不支持的接入点返回空字符串
+func accessPointAddress(clientType DanmuClientType, ap *acproto.AccessPoint, defaultPort uint32) string {
+	if ap == nil {
+		return ""
+	}
+	port := ap.Port
+	if port == 0 {
+		port = defaultPort
+	}
+
+	var host string
+	switch ap.AddressType {
+	case acproto.AccessPoint_kIPV4:
+		if ap.IpV4 == 0 {
+			return ""
+		}
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, ap.IpV4)
+		host = ip.String()
+	case acproto.AccessPoint_kIPV6:
+		if len(ap.IpV6) != net.IPv6len {
+			return ""
+		}
+		host = net.IP(ap.IpV6).String()
+	case acproto.AccessPoint_kDomain:
+		host = ap.Domain
+	default:
+		return ""
+	}
+	if host == "" {
+		return ""
+	}
+
+	switch clientType {
+	case WebSocketDanmuClientType:
+		// WebSocket 连接使用 TLS，只能用域名
+		if ap.AddressType != acproto.AccessPoint_kDomain {
+			return ""
+		}
+		if port == 0 || port == 443 {
+			return "wss://" + host + "/"
+		}
+		return "wss://" + net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10)) + "/"
+	case TCPDanmuClientType:
+		if port == 0 {
+			return ""
+		}
+		return net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10))
+	default:
+		return ""
+	}
+}
+
+// 连接弹幕服务器并接收弹幕数据直到连接断开，正常结束时返回 nil，否则返回导致连接断开的错误
+func (ac *AcFunLive) clientConnect(ctx context.Context, event bool) (e error) {
+	defer func() {
+		if err := recover(); err != nil {
+			e = fmt.Errorf("clientConnect() error: %w", recoverErr(err))
+		}
+	}()
+
 	// 用于关闭 danmuClient
 	clientCtx, clientCancel := context.WithCancel(ctx)
 	defer clientCancel()
 
+	err := ac.dialServer()
+	checkErr(err)
+	ac.stalled.Store(false)
+	ac.lastAlive.Store(time.Now().UnixNano())
+	ac.heartbeat.Store(defaultHeartbeatInterval)
+
+	// 返回前确保连接已经关闭，以免影响重连后的新连接
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		<-clientCtx.Done()
+		_ = ac.danmuClient.Close("")
+	}()
+	defer func() {
+		clientCancel()
+		<-closed
+	}()
+	// 心跳和看门狗 goroutine 会读取连接相关的状态，需要在返回（重连）前退出
+	defer func() {
+		clientCancel()
+		ac.connGoroutines.Wait()
+	}()
+
+	if ac.stallLimit > 0 {
+		ac.connGoroutines.Add(1)
+		go func() {
+			defer ac.connGoroutines.Done()
+			ac.stallWatchdog(clientCtx)
+		}()
+	}
+
 	// WebSocket 连接可以直接发送注册消息，TCP 连接需要先握手
 	if ac.danmuClient.Type() == WebSocketDanmuClientType {
-		err := ac.danmuClient.Dial(wsHost)
-		checkErr(err)
 		_, err = ac.danmuClient.Write(ac.t.register())
 		checkErr(err)
 	} else if ac.danmuClient.Type() == TCPDanmuClientType {
-		err := ac.danmuClient.Dial(tcpHost)
-		checkErr(err)
 		_, err = ac.danmuClient.Write(ac.t.handshake())
 		checkErr(err)
 	}
 
-	go func() {
-		<-clientCtx.Done()
-		ac.danmuClient.Close("")
-	}()
-
 	msgCh := make(chan message, queueLen)
 	payloadCh := make(chan *acproto.DownstreamPayload, queueLen)
-	hasError := false
+	var connErr error
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -286,19 +572,19 @@ func (ac *AcFunLive) clientStart(ctx context.Context, event bool, errCh chan<- e
 				if acErr != nil {
 					err = acErr
 				}
-				if !(errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)) {
-					log.Printf("接收弹幕数据出现错误：%v", err)
-					log.Printf("停止获取 uid 为 %d 的主播的直播弹幕", ac.t.liverUID)
-					hasError = true
-					errCh <- err
-					close(errCh)
-					if event {
-						ac.callEvent(stopDanmu, err)
-					}
+				if ac.stalled.Load() {
+					// 看门狗关闭了停滞的连接
+					connErr = ErrConnectionStalled
+				} else if !(errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)) {
+					connErr = err
+				} else if ac.reconnect != nil && clientCtx.Err() == nil && !ac.t.closed.Load() {
+					// 不是主动关闭的连接，需要重连
+					connErr = fmt.Errorf("弹幕服务器断开了连接：%w", err)
 				}
 				putBytes(msg)
 				break
 			}
+			_ = ac.stats.bytesReceived.Add(uint64(n))
 			msgCh <- message{bytes: msg, len: n}
 		}
 	}()
@@ -307,7 +593,9 @@ func (ac *AcFunLive) clientStart(ctx context.Context, event bool, errCh chan<- e
 	go func() {
 		defer wg.Done()
 		defer close(payloadCh)
-		remain := []byte{}
+		// TCP 连接未分帧的数据，分帧后剩余的数据会移到开头，复用同一个缓冲区
+		var buf []byte
+		var frames [][]byte
 		for msg := range msgCh {
 			if msg.bytes == nil {
 				continue
@@ -316,71 +604,83 @@ func (ac *AcFunLive) clientStart(ctx context.Context, event bool, errCh chan<- e
 			if ac.danmuClient.Type() == WebSocketDanmuClientType {
 				// WebSocket 连接的数据自动分帧
 				stream, err := ac.t.decode(msg.data())
+				putBytes(msg.bytes)
 				if err != nil {
-					log.Printf("解码接收到的弹幕数据出现错误：%v", err)
-					putBytes(msg.bytes)
+					ac.t.logger().Error("解码接收到的弹幕数据出现错误", "error", err)
+					_ = ac.stats.decodeErrors.Inc()
 					continue
 				}
-				putBytes(msg.bytes)
+				ac.lastAlive.Store(ac.stats.frameReceived())
+				ac.captureStream(stream)
 				payloadCh <- stream
 			} else if ac.danmuClient.Type() == TCPDanmuClientType {
 				// TCP 连接的数据需要自行分帧
-				if remain != nil {
-					remain = append(remain, msg.data()...)
-				} else {
-					remain = append([]byte{}, msg.data()...)
-				}
+				buf = append(buf, msg.data()...)
+				putBytes(msg.bytes)
 
-				if len(remain) > 12 {
-					var frames [][]byte
+				if len(buf) > 12 {
+					var remain []byte
 					var err error
-					frames, remain, err = getFrames(remain)
+					frames, remain, err = getFrames(frames[:0], buf)
 					if err != nil {
-						log.Printf("解码接收到的弹幕数据出现错误：%v", err)
-						putBytes(msg.bytes)
+						ac.t.logger().Error("解码接收到的弹幕数据出现错误", "error", err)
+						_ = ac.stats.decodeErrors.Inc()
+						buf = buf[:0]
 						continue
 					}
 
 					for _, frame := range frames {
 						stream, err := ac.t.decode(frame)
 						if err != nil {
-							log.Printf("解码接收到的弹幕数据出现错误：%v", err)
+							ac.t.logger().Error("解码接收到的弹幕数据出现错误", "error", err)
+							_ = ac.stats.decodeErrors.Inc()
 							continue
 						}
+						ac.lastAlive.Store(ac.stats.frameReceived())
+						ac.captureStream(stream)
 						payloadCh <- stream
 					}
+					clear(frames)
+					buf = append(buf[:0], remain...)
 				}
-
-				putBytes(msg.bytes)
 			}
 		}
 	}()
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for stream := range payloadCh {
-			go func(stream *acproto.DownstreamPayload) {
+	// 用固定数量的 goroutine 处理弹幕数据，有序分发时只用一个
+	workers := ac.workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if ac.dispatchMode == DispatchOrdered {
+		workers = 1
+	}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for stream := range payloadCh {
 				err := ac.handleCommand(clientCtx, stream, event)
 				if err != nil {
-					log.Printf("处理接收到的弹幕数据出现错误：%v", err)
+					ac.t.logger().Error("处理接收到的弹幕数据出现错误", "command", stream.Command, "error", err)
 				}
-			}(stream)
-		}
-	}()
+				downstreamPool.put(stream)
+			}
+		}()
+	}
 
 	wg.Wait()
-	if !hasError {
-		errCh <- nil
-		close(errCh)
-		if event {
-			ac.callEvent(stopDanmu, nil)
-		}
+	// 连接可能在处理完弹幕服务器最后发送的数据（如下播通知）之前就已经断开
+	if connErr == nil {
+		connErr = ac.t.err.Load()
 	}
+
+	return connErr
 }
 
 // 停止弹幕 client
 func (ac *AcFunLive) clientStop(message string) {
+	ac.t.closed.Store(true)
 	_, err := ac.danmuClient.Write(ac.t.userExit())
 	checkErr(err)
 	_, err = ac.danmuClient.Write(ac.t.unregister())
@@ -416,10 +716,9 @@ func getFrame(data []byte) ([]byte, []byte) {
 	}
 }
 
-// 获取弹幕数据
-func getFrames(data []byte) ([][]byte, []byte, error) {
+// 获取弹幕数据，分出的帧会追加到 frames 后面，帧的数据引用 data
+func getFrames(frames [][]byte, data []byte) ([][]byte, []byte, error) {
 	if len(data) > 12 {
-		frames := [][]byte{}
 		var frame []byte
 		remain := data
 
@@ -434,16 +733,12 @@ func getFrames(data []byte) ([][]byte, []byte, error) {
 				frames = append(frames, frame)
 				return frames, nil, nil
 			} else if frame == nil && remain != nil {
-				if len(frames) > 0 {
-					return frames, remain, nil
-				} else {
-					return nil, remain, nil
-				}
+				return frames, remain, nil
 			} else {
-				return nil, nil, fmt.Errorf("错误的弹幕数据格式")
+				return frames, nil, fmt.Errorf("错误的弹幕数据格式")
 			}
 		}
 	} else {
-		return nil, data, nil
+		return frames, data, nil
 	}
 }
@@ -0,0 +1,134 @@
+package acfundanmu
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 礼物没有 SlotDisplayDuration 时判定连击结束的默认时间
+const defaultGiftComboTimeout = 3 * time.Second
+
+// GiftComboEnd 礼物连击结束，汇总同一个 ComboID 的所有礼物
+type GiftComboEnd struct {
+	UserInfo   `json:"userInfo"`   // 送礼物的用户的信息
+	GiftDetail `json:"giftDetail"` // 礼物详细信息
+	ComboID    string              `json:"comboID"`    // 礼物连击 ID
+	StartTime  int64               `json:"startTime"`  // 第一次送礼物的时间，是以毫秒为单位的 Unix 时间
+	EndTime    int64               `json:"endTime"`    // 最后一次送礼物的时间，是以毫秒为单位的 Unix 时间
+	Steps      int                 `json:"steps"`      // 收到的礼物弹幕的数量
+	TotalCount int64               `json:"totalCount"` // 礼物总数
+	ACCoin     int64               `json:"acCoin"`     // 礼物总价值，单位为 AC 币，免费礼物（香蕉）为 0
+}
+
+// 正在连击的礼物
+type giftCombo struct {
+	end   GiftComboEnd
+	seq   uint64
+	timer *time.Timer
+}
+
+// 所有正在连击的礼物，零值可以直接使用
+type giftCombos struct {
+	sync.Mutex
+	combos map[string]*giftCombo
+}
+
+// 礼物连击的 key，没有 ComboID 时用用户 uid 和礼物 ID 代替
+func giftComboKey(g *Gift) string {
+	if g.ComboID != "" {
+		return g.ComboID
+	}
+
+	return strconv.FormatInt(g.UserID, 10) + "-" + strconv.FormatInt(g.GiftID, 10)
+}
+
+// 加入一次礼物连击，SlotDisplayDuration 内没有同一个连击的礼物时连击结束
+func (ac *AcFunLive) addGiftCombo(g *Gift, event bool) {
+	key := giftComboKey(g)
+	timeout := time.Duration(g.SlotDisplayDuration) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultGiftComboTimeout
+	}
+	combo := int64(g.Combo)
+	if combo <= 0 {
+		combo = 1
+	}
+	// Combo 是累计的连击数，用最大值计算礼物总数，避免重复计算每一次连击
+	count := int64(g.Count) * combo
+
+	ac.combos.Lock()
+	defer ac.combos.Unlock()
+	if ac.combos.combos == nil {
+		ac.combos.combos = make(map[string]*giftCombo)
+	}
+	c, ok := ac.combos.combos[key]
+	if !ok {
+		c = &giftCombo{
+			end: GiftComboEnd{
+				UserInfo:   g.UserInfo,
+				GiftDetail: g.GiftDetail,
+				ComboID:    g.ComboID,
+				StartTime:  g.SendTime,
+			},
+		}
+		ac.combos.combos[key] = c
+	} else {
+		c.timer.Stop()
+	}
+	// 并行分发时礼物不一定按顺序到达
+	if g.SendTime < c.end.StartTime {
+		c.end.StartTime = g.SendTime
+	}
+	if g.SendTime > c.end.EndTime {
+		c.end.EndTime = g.SendTime
+	}
+	c.end.Steps++
+	if count > c.end.TotalCount {
+		c.end.TotalCount = count
+	}
+
+	c.seq++
+	seq := c.seq
+	c.timer = time.AfterFunc(timeout, func() {
+		ac.combos.Lock()
+		if ac.combos.combos[key] != c || c.seq != seq {
+			ac.combos.Unlock()
+			return
+		}
+		delete(ac.combos.combos, key)
+		ac.combos.Unlock()
+		ac.emitEvent(event, EventGiftComboEnd, ac.finishGiftCombo(c.end))
+	})
+}
+
+// 结束所有正在连击的礼物，在弹幕获取结束时调用
+func (ac *AcFunLive) flushGiftCombos(event bool) {
+	ac.combos.Lock()
+	combos := ac.combos.combos
+	ac.combos.combos = nil
+	for _, c := range combos {
+		c.timer.Stop()
+	}
+	ac.combos.Unlock()
+
+	for _, c := range combos {
+		ac.emitEvent(event, EventGiftComboEnd, ac.finishGiftCombo(c.end))
+	}
+}
+
+// 计算礼物总价值，礼物价格未知时从礼物列表里获取
+func (ac *AcFunLive) finishGiftCombo(e GiftComboEnd) *GiftComboEnd {
+	if e.Price == 0 {
+		ac.t.giftsMutex.RLock()
+		if g, ok := ac.t.gifts[e.GiftID]; ok {
+			e.GiftDetail = g
+		}
+		ac.t.giftsMutex.RUnlock()
+	}
+	if e.PayWalletType == 1 {
+		e.ACCoin = e.TotalCount * int64(e.Price)
+	}
+
+	return &e
+}
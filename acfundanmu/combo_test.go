@@ -0,0 +1,154 @@
+package acfundanmu
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/orzogc/acfundanmu/acproto"
+)
+
+// 返回设置了礼物列表的 *AcFunLive 和接收 GiftComboEnd 的 channel
+func comboAcFunLive(t *testing.T) (*AcFunLive, <-chan *GiftComboEnd) {
+	t.Helper()
+	ac := replayAcFunLive(t, []*acproto.DownstreamPayload{{}}, SetEventDispatchMode(DispatchOrdered))
+	ac.t.gifts[1] = GiftDetail{GiftID: 1, GiftName: "付费礼物", Price: 10, PayWalletType: 1}
+	ac.t.gifts[2] = GiftDetail{GiftID: 2, GiftName: "香蕉", Price: 1, PayWalletType: 2}
+	ch := make(chan *GiftComboEnd, 10)
+	ac.OnGiftComboEnd(func(_ *AcFunLive, e *GiftComboEnd) {
+		ch <- e
+	})
+
+	return ac, ch
+}
+
+func TestGiftCombo(t *testing.T) {
+	type gift struct {
+		uid      int64
+		giftID   int64
+		comboID  string
+		count    int32
+		combo    int32
+		sendTime int64
+	}
+	tests := []struct {
+		name  string
+		gifts []gift
+		want  []GiftComboEnd // 按 ComboID 和 StartTime 排序
+	}{
+		{"累计连击", []gift{{10, 1, "a", 1, 1, 100}, {10, 1, "a", 1, 2, 200}, {10, 1, "a", 1, 3, 300}}, []GiftComboEnd{
+			{ComboID: "a", StartTime: 100, EndTime: 300, Steps: 3, TotalCount: 3, ACCoin: 30},
+		}},
+		{"乱序到达", []gift{{10, 1, "a", 1, 2, 200}, {10, 1, "a", 1, 1, 100}}, []GiftComboEnd{
+			{ComboID: "a", StartTime: 100, EndTime: 200, Steps: 2, TotalCount: 2, ACCoin: 20},
+		}},
+		{"批量赠送", []gift{{10, 1, "a", 10, 1, 100}, {10, 1, "a", 10, 2, 200}}, []GiftComboEnd{
+			{ComboID: "a", StartTime: 100, EndTime: 200, Steps: 2, TotalCount: 20, ACCoin: 200},
+		}},
+		{"没有连击数", []gift{{10, 1, "a", 5, 0, 100}}, []GiftComboEnd{
+			{ComboID: "a", StartTime: 100, EndTime: 100, Steps: 1, TotalCount: 5, ACCoin: 50},
+		}},
+		{"免费礼物", []gift{{10, 2, "a", 1, 1, 100}, {10, 2, "a", 1, 2, 200}}, []GiftComboEnd{
+			{ComboID: "a", StartTime: 100, EndTime: 200, Steps: 2, TotalCount: 2, ACCoin: 0},
+		}},
+		{"不同连击", []gift{{10, 1, "a", 1, 1, 100}, {11, 2, "b", 1, 1, 150}, {10, 1, "a", 1, 2, 200}}, []GiftComboEnd{
+			{ComboID: "a", StartTime: 100, EndTime: 200, Steps: 2, TotalCount: 2, ACCoin: 20},
+			{ComboID: "b", StartTime: 150, EndTime: 150, Steps: 1, TotalCount: 1, ACCoin: 0},
+		}},
+		{"没有 ComboID", []gift{{10, 1, "", 1, 1, 100}, {10, 1, "", 1, 2, 200}, {11, 1, "", 1, 1, 300}}, []GiftComboEnd{
+			{StartTime: 100, EndTime: 200, Steps: 2, TotalCount: 2, ACCoin: 20},
+			{StartTime: 300, EndTime: 300, Steps: 1, TotalCount: 1, ACCoin: 10},
+		}},
+	}
+	for _, tt := range tests {
+		for _, flush := range []bool{true, false} {
+			name := tt.name + "/超时"
+			if flush {
+				name = tt.name + "/结束时"
+			}
+			t.Run(name, func(t *testing.T) {
+				ac, ch := comboAcFunLive(t)
+				duration := int64(20)
+				if flush {
+					duration = time.Hour.Milliseconds()
+				}
+				for _, g := range tt.gifts {
+					ac.addGiftCombo(&Gift{
+						DanmuCommon: DanmuCommon{SendTime: g.sendTime, UserInfo: UserInfo{UserID: g.uid}},
+						// 价格未知时从礼物列表里获取
+						GiftDetail:          GiftDetail{GiftID: g.giftID},
+						Count:               g.count,
+						Combo:               g.combo,
+						ComboID:             g.comboID,
+						SlotDisplayDuration: duration,
+					}, true)
+				}
+				if flush {
+					ac.flushGiftCombos(true)
+				}
+
+				var got []GiftComboEnd
+				for range tt.want {
+					select {
+					case e := <-ch:
+						got = append(got, *e)
+					case <-time.After(time.Second):
+						t.Fatalf("got %d GiftComboEnd, want %d", len(got), len(tt.want))
+					}
+				}
+				slices.SortFunc(got, func(a, b GiftComboEnd) int {
+					if c := strings.Compare(a.ComboID, b.ComboID); c != 0 {
+						return c
+					}
+					return cmp.Compare(a.StartTime, b.StartTime)
+				})
+				for i, e := range got {
+					w := tt.want[i]
+					if e.ComboID != w.ComboID || e.StartTime != w.StartTime || e.EndTime != w.EndTime ||
+						e.Steps != w.Steps || e.TotalCount != w.TotalCount || e.ACCoin != w.ACCoin {
+						t.Errorf("GiftComboEnd %d = %+v, want %+v", i, e, w)
+					}
+					if e.GiftName == "" || e.Price == 0 {
+						t.Errorf("GiftComboEnd %d has no gift detail: %+v", i, e.GiftDetail)
+					}
+				}
+
+				ac.flushGiftCombos(true)
+				select {
+				case e := <-ch:
+					t.Errorf("unexpected GiftComboEnd %+v", e)
+				case <-time.After(50 * time.Millisecond):
+				}
+			})
+		}
+	}
+}
+
+// 连击期间再次送礼物会重新计时
+func TestGiftComboTimerReset(t *testing.T) {
+	ac, ch := comboAcFunLive(t)
+	gift := func(combo int32) *Gift {
+		return &Gift{
+			DanmuCommon:         DanmuCommon{SendTime: int64(combo), UserInfo: UserInfo{UserID: 10}},
+			GiftDetail:          GiftDetail{GiftID: 1},
+			Count:               1,
+			Combo:               combo,
+			ComboID:             "a",
+			SlotDisplayDuration: 100,
+		}
+	}
+	for combo := range int32(4) {
+		ac.addGiftCombo(gift(combo+1), true)
+		time.Sleep(40 * time.Millisecond)
+	}
+	select {
+	case e := <-ch:
+		if e.Steps != 4 || e.TotalCount != 4 {
+			t.Errorf("GiftComboEnd = %+v, want 4 steps and 4 gifts", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no GiftComboEnd after the combo timeout")
+	}
+}
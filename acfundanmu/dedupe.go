@@ -0,0 +1,142 @@
+package acfundanmu
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDedupeWindow     = 5 * time.Minute // 去重时默认记住弹幕的时间
+	defaultDedupeMaxEntries = 10000           // 去重时每个主播默认最多记住的弹幕数量
+)
+
+// 判断弹幕是否重复的 key
+type dedupeKey struct {
+	t        EventType
+	userID   int64
+	sendTime int64
+	content  string
+}
+
+// 记住的弹幕
+type dedupeEntry struct {
+	key  dedupeKey
+	seen time.Time
+}
+
+// 记住一个主播最近一段时间的弹幕
+type deduper struct {
+	sync.Mutex
+	window     time.Duration
+	maxEntries int
+	keys       map[dedupeKey]struct{}
+	entries    []dedupeEntry // 按记住的时间排序
+	head       int
+}
+
+// 返回 key 是否出现过，没有出现过时记住 key
+func (d *deduper) seen(key dedupeKey, now time.Time) bool {
+	// 删除过期的弹幕
+	for d.head < len(d.entries) && now.Sub(d.entries[d.head].seen) > d.window {
+		d.evict()
+	}
+
+	if _, ok := d.keys[key]; ok {
+		return true
+	}
+	// 记住的弹幕太多时删除最旧的弹幕
+	for len(d.entries)-d.head >= d.maxEntries {
+		d.evict()
+	}
+	if d.head > len(d.entries)/2 {
+		d.entries = append(d.entries[:0], d.entries[d.head:]...)
+		d.head = 0
+	}
+	d.keys[key] = struct{}{}
+	d.entries = append(d.entries, dedupeEntry{key: key, seen: now})
+
+	return false
+}
+
+// 删除最旧的弹幕
+func (d *deduper) evict() {
+	delete(d.keys, d.entries[d.head].key)
+	d.entries[d.head] = dedupeEntry{}
+	d.head++
+}
+
+// 返回弹幕的 key，没有发送时间的弹幕无法判断是否重复，ok 为 false
+func danmuDedupeKey(t EventType, d DanmuMessage) (key dedupeKey, ok bool) {
+	key = dedupeKey{
+		t:        t,
+		sendTime: d.GetSendTime(),
+	}
+	if key.sendTime == 0 {
+		return key, false
+	}
+	if u := d.GetUserInfo(); u != nil {
+		key.userID = u.UserID
+	}
+	switch d := d.(type) {
+	case *Comment:
+		key.content = d.Content
+	case *Gift:
+		key.content = d.ComboID + "-" + strconv.FormatInt(d.GiftID, 10) + "-" + strconv.Itoa(int(d.Combo))
+	}
+
+	return key, true
+}
+
+// NewDedupeMiddleware 返回去除重复弹幕的事件中间件，主播 uid、弹幕类型、用户 uid、发送时间和内容（评论的文字或礼物的连击）都相同的弹幕视为重复。
+// window 为记住弹幕的时间，小于等于 0 时默认为 5 分钟；maxEntries 为每个主播最多记住的弹幕数量，超过时忘记最旧的弹幕，小于等于 0 时默认为 10000。
+// 每个主播的弹幕分开记住，不同主播的 AcFunLive 之间不会互相等待。OnRecentComment 的评论会去除已经出现过的评论，全部重复时丢弃事件。
+// 可以用于事件响应模式和 GetDanmu() 的队列，重连后重复收到的弹幕也会被去除。
+func NewDedupeMiddleware(window time.Duration, maxEntries int) EventMiddleware {
+	if window <= 0 {
+		window = defaultDedupeWindow
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultDedupeMaxEntries
+	}
+	var dedupers sync.Map // 主播 uid 对应的 *deduper
+
+	return func(ac *AcFunLive, e *Event) bool {
+		liverUID := ac.GetLiverUID()
+		v, ok := dedupers.Load(liverUID)
+		if !ok {
+			v, _ = dedupers.LoadOrStore(liverUID, &deduper{
+				window:     window,
+				maxEntries: maxEntries,
+				keys:       make(map[dedupeKey]struct{}),
+			})
+		}
+		d := v.(*deduper)
+		now := time.Now()
+		d.Lock()
+		defer d.Unlock()
+
+		if comments, ok := e.Data.([]Comment); ok && e.Type == EventRecentComment {
+			recent := make([]Comment, 0, len(comments))
+			for i := range comments {
+				key, ok := danmuDedupeKey(EventComment, &comments[i])
+				if !ok || !d.seen(key, now) {
+					recent = append(recent, comments[i])
+				}
+			}
+			if len(recent) == 0 {
+				return false
+			}
+			e.Data = recent
+			return true
+		}
+
+		if danmu, ok := e.Data.(DanmuMessage); ok {
+			if key, ok := danmuDedupeKey(e.Type, danmu); ok {
+				return !d.seen(key, now)
+			}
+		}
+
+		return true
+	}
+}
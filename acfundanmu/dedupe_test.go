@@ -0,0 +1,86 @@
+package acfundanmu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeduper(t *testing.T) {
+	type step struct {
+		content string
+		at      time.Duration // 相对开始时间
+		seen    bool
+	}
+	tests := []struct {
+		name       string
+		window     time.Duration
+		maxEntries int
+		steps      []step
+	}{
+		{"重复", time.Minute, 10, []step{
+			{"a", 0, false}, {"b", 0, false}, {"a", time.Second, true}, {"b", 2 * time.Second, true},
+		}},
+		{"过期后不算重复", time.Minute, 10, []step{
+			{"a", 0, false}, {"a", time.Minute, true}, {"a", time.Minute + time.Second, false}, {"a", 2 * time.Minute, true},
+		}},
+		{"超过数量时忘记最旧的", time.Minute, 2, []step{
+			{"a", 0, false}, {"b", 0, false}, {"c", 0, false}, {"b", 0, true}, {"c", 0, true}, {"a", 0, false}, {"b", 0, false},
+		}},
+		{"只记住一个", time.Minute, 1, []step{
+			{"a", 0, false}, {"a", 0, true}, {"b", 0, false}, {"a", 0, false},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &deduper{window: tt.window, maxEntries: tt.maxEntries, keys: make(map[dedupeKey]struct{})}
+			start := time.Now()
+			for i, s := range tt.steps {
+				key := dedupeKey{t: EventComment, userID: 1, sendTime: 1, content: s.content}
+				if got := d.seen(key, start.Add(s.at)); got != s.seen {
+					t.Errorf("step %d: seen(%q) = %v, want %v", i, s.content, got, s.seen)
+				}
+				if n := len(d.entries) - d.head; n > tt.maxEntries || n != len(d.keys) {
+					t.Fatalf("step %d: %d entries and %d keys, max %d", i, n, len(d.keys), tt.maxEntries)
+				}
+			}
+		})
+	}
+}
+
+func TestDedupeMiddleware(t *testing.T) {
+	comment := func(uid, sendTime int64, content string) *Comment {
+		return &Comment{DanmuCommon: DanmuCommon{SendTime: sendTime, UserInfo: UserInfo{UserID: uid}}, Content: content}
+	}
+	ac1 := &AcFunLive{t: &token{liverUID: 1}}
+	ac2 := &AcFunLive{t: &token{liverUID: 2}}
+	tests := []struct {
+		name string
+		ac   *AcFunLive
+		e    Event
+		want bool
+	}{
+		{"新评论", ac1, Event{Type: EventComment, Data: comment(10, 1, "弹幕")}, true},
+		{"重复评论", ac1, Event{Type: EventComment, Data: comment(10, 1, "弹幕")}, false},
+		{"内容不同", ac1, Event{Type: EventComment, Data: comment(10, 1, "弹幕2")}, true},
+		{"其他主播", ac2, Event{Type: EventComment, Data: comment(10, 1, "弹幕")}, true},
+		{"没有发送时间", ac1, Event{Type: EventComment, Data: comment(10, 0, "弹幕")}, true},
+		{"没有发送时间再次收到", ac1, Event{Type: EventComment, Data: comment(10, 0, "弹幕")}, true},
+		{"点赞", ac1, Event{Type: EventLike, Data: &Like{SendTime: 1, UserInfo: UserInfo{UserID: 10}}}, true},
+		{"重复点赞", ac1, Event{Type: EventLike, Data: &Like{SendTime: 1, UserInfo: UserInfo{UserID: 10}}}, false},
+		{"不是弹幕", ac1, Event{Type: EventDanmuStop, Data: ErrLiveClosed}, true},
+		{"最近评论部分重复", ac1, Event{Type: EventRecentComment, Data: []Comment{*comment(10, 1, "弹幕"), *comment(11, 2, "新弹幕")}}, true},
+		{"最近评论全部重复", ac1, Event{Type: EventRecentComment, Data: []Comment{*comment(10, 1, "弹幕"), *comment(11, 2, "新弹幕")}}, false},
+	}
+	middleware := NewDedupeMiddleware(0, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.e
+			if got := middleware(tt.ac, &e); got != tt.want {
+				t.Fatalf("middleware() = %v, want %v", got, tt.want)
+			}
+			if comments, ok := e.Data.([]Comment); ok && tt.want && (len(comments) != 1 || comments[0].Content != "新弹幕") {
+				t.Errorf("recent comments = %+v, want only the new one", comments)
+			}
+		})
+	}
+}
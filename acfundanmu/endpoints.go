@@ -0,0 +1,84 @@
+package acfundanmu
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/atomic"
+)
+
+// Endpoints 设置替换 API 的 host，格式为 scheme://host[:port]（如 http://127.0.0.1:8080），为空时不替换。
+// 可以用于测试时指向本地的 mock 服务器，或者在 AcFun 更换域名时使用新的域名，API 的路径和参数不变。
+type Endpoints struct {
+	KuaiShouZT string `json:"kuaiShouZT"` // 替换 api.kuaishouzt.com
+	Live       string `json:"live"`       // 替换 live.acfun.cn
+	ID         string `json:"id"`         // 替换 id.app.acfun.cn
+	Member     string `json:"member"`     // 替换 member.acfun.cn
+	Scan       string `json:"scan"`       // 替换 scan.acfun.cn
+	Main       string `json:"main"`       // 替换 www.acfun.cn
+}
+
+// 原 host 对应的替换后的 URL
+type endpointMap map[string]*url.URL
+
+// 全局的 host 替换
+var defaultEndpoints atomic.Pointer[endpointMap]
+
+// SetDefaultEndpoints 设置全局的 host 替换，用于所有没有用 SetEndpoints() 设置的 HTTP 请求，包括 Login() 和 GetUserLiveInfo() 等不需要 AcFunLive 的函数。
+// 应该在发起请求前调用，会替换之前的设置。
+func SetDefaultEndpoints(endpoints Endpoints) error {
+	m, err := endpoints.parse()
+	if err != nil {
+		return err
+	}
+	defaultEndpoints.Store(m)
+
+	return nil
+}
+
+// 解析 Endpoints，没有需要替换的 host 时返回 nil
+func (e Endpoints) parse() (*endpointMap, error) {
+	m := make(endpointMap)
+	for host, endpoint := range map[string]string{
+		"api.kuaishouzt.com": e.KuaiShouZT,
+		"live.acfun.cn":      e.Live,
+		"id.app.acfun.cn":    e.ID,
+		"member.acfun.cn":    e.Member,
+		"scan.acfun.cn":      e.Scan,
+		"www.acfun.cn":       e.Main,
+	} {
+		if endpoint == "" {
+			continue
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("替换 %s 的 %s 格式错误：%w", host, endpoint, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("替换 %s 的 %s 格式错误，应为 scheme://host[:port]", host, endpoint)
+		}
+		m[host] = u
+	}
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	return &m, nil
+}
+
+// 替换 req 的 host，m 为 nil 时使用全局的设置
+func (m *endpointMap) rewrite(req *fasthttp.Request) {
+	if m == nil {
+		m = defaultEndpoints.Load()
+		if m == nil {
+			return
+		}
+	}
+
+	uri := req.URI()
+	if u, ok := (*m)[string(uri.Host())]; ok {
+		uri.SetScheme(u.Scheme)
+		uri.SetHost(u.Host)
+	}
+}
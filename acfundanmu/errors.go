@@ -0,0 +1,89 @@
+package acfundanmu
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/valyala/fastjson"
+)
+
+var (
+	// ErrLiveClosed 主播下播导致弹幕获取结束
+	ErrLiveClosed = errors.New("直播已结束")
+	// ErrLiveBanned 直播间被封禁导致弹幕获取结束，具体的封禁理由会包含在错误信息里
+	ErrLiveBanned = errors.New("直播间被封禁")
+	// ErrKickedOut 登陆帐号被踢出直播间导致弹幕获取结束，具体的理由会包含在错误信息里
+	ErrKickedOut = errors.New("被踢出直播间")
+	// ErrTokenExpired 令牌失效（弹幕服务器返回错误码 10018）导致弹幕获取结束，需要重新登陆或调用 NewAcFunLive()
+	ErrTokenExpired = errors.New("令牌已失效")
+	// ErrUnregistered 弹幕服务器发送 Unregister 信号导致弹幕获取结束
+	ErrUnregistered = errors.New("弹幕服务器取消了注册")
+	// ErrNotLive 主播没有在直播，根据主播的直播信息里是否有 liveID 判断
+	ErrNotLive = errors.New("主播没有在直播")
+	// ErrConnectionStalled 弹幕连接停滞（很久没有收到心跳回应和弹幕数据），设置了自动重连时会重连
+	ErrConnectionStalled = errors.New("弹幕连接停滞")
+	// ErrNotLoggedIn 调用的 API 需要登陆 AcFun 帐号
+	ErrNotLoggedIn = errors.New("需要登陆 AcFun 帐号")
+)
+
+// 弹幕服务器返回的令牌失效的错误码
+const tokenExpiredCode = 10018
+
+// APIError 调用 AcFun 或快手的 API 失败时返回的错误，可以用 errors.As 获取
+type APIError struct {
+	Code    int    // 响应里的 result
+	Message string // 响应里的错误信息，可能为空
+	Body    []byte // 响应的 body
+	op      string // 调用的 API 的描述
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s，响应为 %s", e.op, string(e.Body))
+}
+
+// 生成 APIError，op 为失败的操作的描述，v 为解析后的响应，可以为 nil
+func newAPIError(op string, v *fastjson.Value, body []byte) *APIError {
+	e := &APIError{
+		Body: append([]byte{}, body...),
+		op:   op,
+	}
+	if v != nil {
+		e.Code = v.GetInt("result")
+		e.Message = string(apiErrorMessage(v))
+	}
+
+	return e
+}
+
+// 返回响应里的错误信息，没有时返回 nil
+func apiErrorMessage(v *fastjson.Value) []byte {
+	for _, key := range []string{"error_msg", "errorMsg", "msg", "message"} {
+		if msg := v.GetStringBytes(key); len(msg) != 0 {
+			return msg
+		}
+	}
+
+	return nil
+}
+
+// 错误信息为 msg 并包装 err 的错误
+type wrapError struct {
+	msg string
+	err error
+}
+
+// Error 实现 error 接口
+func (e *wrapError) Error() string {
+	return e.msg
+}
+
+// Unwrap 返回被包装的错误
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
+
+// 生成需要登陆 AcFun 帐号的错误，错误信息为 msg
+func errNeedLogin(msg string) error {
+	return &wrapError{msg: msg, err: ErrNotLoggedIn}
+}
@@ -1,81 +1,362 @@
 package acfundanmu
 
 import (
-	"log"
+	"fmt"
+	"slices"
 	"sync"
+	"time"
+
+	"go.uber.org/atomic"
 )
 
-// 弹幕信息类型
-type eventType int
+// EventType 事件类型，和 On 开头的方法一一对应，如 EventComment 对应 OnComment()
+type EventType int
 
 const (
-	stopDanmu eventType = iota
-	commentDanmu
-	likeDanmu
-	enterRoomDanmu
-	followAuthorDanmu
-	throwBananaDanmu
-	giftDanmu
-	richTextDanmu
-	joinClubDanmu
-	shareLiveDanmu
-	bananaCountEvent
-	displayEvent
-	topUsersEvent
-	recentCommentEvent
-	chatCallEvent
-	chatAcceptEvent
-	chatReadyEvent
-	chatEndEvent
-	authorChatCallEvent
-	authorChatAcceptEvent
-	authorChatReadyEvent
-	authorChatEndEvent
-	authorChatChangeSoundConfigEvent
-	redpackListEvent
-	kickedOutEvent
-	violationAlertEvent
-	managerStateEvent
+	EventDanmuStop                   EventType = iota // OnDanmuStop()
+	EventComment                                      // OnComment()
+	EventLike                                         // OnLike()
+	EventEnterRoom                                    // OnEnterRoom()
+	EventFollowAuthor                                 // OnFollowAuthor()
+	EventThrowBanana                                  // OnThrowBanana()
+	EventGift                                         // OnGift()
+	EventRichText                                     // OnRichText()
+	EventJoinClub                                     // OnJoinClub()
+	EventShareLive                                    // OnShareLive()
+	EventBananaCount                                  // OnBananaCount()
+	EventDisplayInfo                                  // OnDisplayInfo()
+	EventTopUsers                                     // OnTopUsers()
+	EventRecentComment                                // OnRecentComment()
+	EventChatCall                                     // OnChatCall()
+	EventChatAccept                                   // OnChatAccept()
+	EventChatReady                                    // OnChatReady()
+	EventChatEnd                                      // OnChatEnd()
+	EventAuthorChatCall                               // OnAuthorChatCall()
+	EventAuthorChatAccept                             // OnAuthorChatAccept()
+	EventAuthorChatReady                              // OnAuthorChatReady()
+	EventAuthorChatEnd                                // OnAuthorChatEnd()
+	EventAuthorChatChangeSoundConfig                  // OnAuthorChatChangeSoundConfig()
+	EventRedpackList                                  // OnRedpackList()
+	EventKickedOut                                    // OnKickedOut()
+	EventViolationAlert                               // OnViolationAlert()
+	EventManagerState                                 // OnManagerState()
+	EventReconnecting                                 // OnReconnecting()
+	EventReconnected                                  // OnReconnected()
+	EventPKInvitation                                 // OnPKInvitation()
+	EventPKAccept                                     // OnPKAccept()
+	EventPKReady                                      // OnPKReady()
+	EventPKStatistic                                  // OnPKStatistic()
+	EventPKEnd                                        // OnPKEnd()
+	EventPKSoundConfigChanged                         // OnPKSoundConfigChanged()
+	EventLiveStatusChanged                            // OnLiveStatusChanged()
+	EventAuthorPause                                  // OnAuthorPause()
+	EventAuthorResume                                 // OnAuthorResume()
+	EventWidget                                       // OnWidget()
+	EventWishSheet                                    // OnWishSheet()
+	EventTopBanner                                    // OnTopBanner()
+	EventShoppingCart                                 // OnShoppingCart()
+	EventEcommerceCart                                // OnEcommerceCart()
+	EventEcommerceCartItemPopup                       // OnEcommerceCartItemPopup()
+	EventNewApplyUser                                 // OnNewApplyUser()
+	EventRemoveApplyUser                              // OnRemoveApplyUser()
+	EventFeatureStateSync                             // OnFeatureStateSync()
+	EventCoverAuditResult                             // OnCoverAuditResult()
+	EventUnknownSignal                                // OnUnknownSignal()
+	EventGiftComboEnd                                 // OnGiftComboEnd()
 )
 
+// 事件类型的名字
+var eventNames = [...]string{
+	EventDanmuStop:                   "DanmuStop",
+	EventComment:                     "Comment",
+	EventLike:                        "Like",
+	EventEnterRoom:                   "EnterRoom",
+	EventFollowAuthor:                "FollowAuthor",
+	EventThrowBanana:                 "ThrowBanana",
+	EventGift:                        "Gift",
+	EventRichText:                    "RichText",
+	EventJoinClub:                    "JoinClub",
+	EventShareLive:                   "ShareLive",
+	EventBananaCount:                 "BananaCount",
+	EventDisplayInfo:                 "DisplayInfo",
+	EventTopUsers:                    "TopUsers",
+	EventRecentComment:               "RecentComment",
+	EventChatCall:                    "ChatCall",
+	EventChatAccept:                  "ChatAccept",
+	EventChatReady:                   "ChatReady",
+	EventChatEnd:                     "ChatEnd",
+	EventAuthorChatCall:              "AuthorChatCall",
+	EventAuthorChatAccept:            "AuthorChatAccept",
+	EventAuthorChatReady:             "AuthorChatReady",
+	EventAuthorChatEnd:               "AuthorChatEnd",
+	EventAuthorChatChangeSoundConfig: "AuthorChatChangeSoundConfig",
+	EventRedpackList:                 "RedpackList",
+	EventKickedOut:                   "KickedOut",
+	EventViolationAlert:              "ViolationAlert",
+	EventManagerState:                "ManagerState",
+	EventReconnecting:                "Reconnecting",
+	EventReconnected:                 "Reconnected",
+	EventPKInvitation:                "PKInvitation",
+	EventPKAccept:                    "PKAccept",
+	EventPKReady:                     "PKReady",
+	EventPKStatistic:                 "PKStatistic",
+	EventPKEnd:                       "PKEnd",
+	EventPKSoundConfigChanged:        "PKSoundConfigChanged",
+	EventLiveStatusChanged:           "LiveStatusChanged",
+	EventAuthorPause:                 "AuthorPause",
+	EventAuthorResume:                "AuthorResume",
+	EventWidget:                      "Widget",
+	EventWishSheet:                   "WishSheet",
+	EventTopBanner:                   "TopBanner",
+	EventShoppingCart:                "ShoppingCart",
+	EventEcommerceCart:               "EcommerceCart",
+	EventEcommerceCartItemPopup:      "EcommerceCartItemPopup",
+	EventNewApplyUser:                "NewApplyUser",
+	EventRemoveApplyUser:             "RemoveApplyUser",
+	EventFeatureStateSync:            "FeatureStateSync",
+	EventCoverAuditResult:            "CoverAuditResult",
+	EventUnknownSignal:               "UnknownSignal",
+	EventGiftComboEnd:                "GiftComboEnd",
+}
+
+// String 返回事件类型的名字，如 EventComment 为 "Comment"
+func (t EventType) String() string {
+	if t >= 0 && int(t) < len(eventNames) {
+		return eventNames[t]
+	}
+
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// OnEvent() 注册的 handler 对应的事件类型
+const anyEvent EventType = -1
+
 // 事件 handler
 type eventHandler func(*AcFunLive, any)
 
-// 事件 handler 的 map
+// 注册的事件 handler，id 用于取消注册
+type handlerEntry struct {
+	id uint64
+	f  eventHandler
+}
+
+// 事件 handler 的 id，全局唯一，复制到其他 AcFunLive 的 handler 保持原来的 id
+var handlerID atomic.Uint64
+
+// 事件 handler 的 map，列表只会被替换而不会被原地修改，可以不持有锁遍历已经取出的列表
 type handlerMap struct {
 	sync.RWMutex
-	listMap map[eventType][]eventHandler
+	listMap map[EventType][]handlerEntry
+}
+
+// 将 f 加入到 t 对应的事件 handler 列表里，返回取消注册的函数
+func (h *handlerMap) add(t EventType, f eventHandler) func() {
+	id := handlerID.Inc()
+	h.Lock()
+	defer h.Unlock()
+	h.listMap[t] = append(h.listMap[t], handlerEntry{id: id, f: f})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.remove(t, id)
+		})
+	}
 }
 
-// 将 f 加入到 t 对应的事件 handler 列表里
-func (h *handlerMap) add(t eventType, f eventHandler) {
+// 从 t 对应的事件 handler 列表里删除 id 对应的 handler
+func (h *handlerMap) remove(t EventType, id uint64) {
 	h.Lock()
 	defer h.Unlock()
-	h.listMap[t] = append(h.listMap[t], f)
+	list := h.listMap[t]
+	i := slices.IndexFunc(list, func(e handlerEntry) bool { return e.id == id })
+	if i < 0 {
+		return
+	}
+	if len(list) == 1 {
+		delete(h.listMap, t)
+		return
+	}
+	// 不能原地删除，其他地方可能正在遍历原来的列表
+	newList := make([]handlerEntry, 0, len(list)-1)
+	newList = append(newList, list[:i]...)
+	h.listMap[t] = append(newList, list[i+1:]...)
+}
+
+// RemoveAllHandlers 删除 t 对应的所有事件 handler，之前 On 开头的方法返回的函数不会再起作用
+func (ac *AcFunLive) RemoveAllHandlers(t EventType) {
+	ac.handlerMap.Lock()
+	defer ac.handlerMap.Unlock()
+	delete(ac.handlerMap.listMap, t)
+}
+
+// EventMiddleware 事件中间件，在事件分发给事件 handler、Events() 和 GetDanmu() 的队列前按设置的顺序调用，需要支持并行调用。
+// 可以修改 e.Data 指向的数据（如 *Comment 的 Content）或将 e.Data 替换为同类型的新数据，不能修改 e.Type。
+// 返回 false 时丢弃事件，之后的中间件不会被调用。EventDanmuStop 不会被丢弃，以免错过弹幕获取结束的通知。
+type EventMiddleware func(ac *AcFunLive, e *Event) bool
+
+// 用事件中间件处理事件，返回 false 时丢弃事件，EventDanmuStop 总是返回 true
+func (ac *AcFunLive) applyEventMiddleware(e *Event) bool {
+	stop := e.Type == EventDanmuStop
+	for _, m := range ac.eventMiddlewares {
+		if !m(ac, e) {
+			return stop
+		}
+	}
+
+	return true
+}
+
+// NewBlockUserMiddleware 返回屏蔽用户的事件中间件，丢弃 uids 里的用户的弹幕（事件数据实现了 DanmuMessage）
+func NewBlockUserMiddleware(uids ...int64) EventMiddleware {
+	blocked := make(map[int64]struct{}, len(uids))
+	for _, uid := range uids {
+		blocked[uid] = struct{}{}
+	}
+
+	return func(_ *AcFunLive, e *Event) bool {
+		if d, ok := e.Data.(DanmuMessage); ok {
+			if u := d.GetUserInfo(); u != nil {
+				_, ok = blocked[u.UserID]
+				return !ok
+			}
+		}
+
+		return true
+	}
+}
+
+// 返回 DanmuMessage 对应的事件类型
+func danmuEventType(d DanmuMessage) (EventType, bool) {
+	switch d.(type) {
+	case *Comment:
+		return EventComment, true
+	case *Like:
+		return EventLike, true
+	case *EnterRoom:
+		return EventEnterRoom, true
+	case *FollowAuthor:
+		return EventFollowAuthor, true
+	case *ThrowBanana:
+		return EventThrowBanana, true
+	case *Gift:
+		return EventGift, true
+	case *RichText:
+		return EventRichText, true
+	case *JoinClub:
+		return EventJoinClub, true
+	case *ShareLive:
+		return EventShareLive, true
+	default:
+		return 0, false
+	}
+}
+
+// 事件响应模式下调用事件 handler，否则将事件放入队列
+func (ac *AcFunLive) emitEvent(event bool, t EventType, i any) {
+	if event {
+		ac.callEvent(t, i)
+	} else {
+		ac.queueEvent(t, i)
+	}
+}
+
+// 将事件放入队列，没有设置 SetQueueAllEvents(true) 时只放入弹幕
+func (ac *AcFunLive) queueEvent(t EventType, i any) {
+	if _, ok := i.(DanmuMessage); !ok && !ac.queueAll {
+		return
+	}
+	// 弹幕获取结束时队列会被关闭，不需要放入队列
+	if t == EventDanmuStop {
+		ac.flushGiftCombos(false)
+		return
+	}
+
+	e := Event{Type: t, Data: i}
+	if !ac.applyEventMiddleware(&e) {
+		return
+	}
+	e.Seq = ac.eventSeq.Inc()
+	d, ok := e.Data.(DanmuMessage)
+	if !ok {
+		d = &EventMessage{Event: e, ReceiveTime: time.Now().UnixMilli()}
+	}
+	if g, ok := d.(*Gift); ok && ac.queueAll {
+		ac.addGiftCombo(g, false)
+	}
+	ac.q.put(d)
 }
 
 // 调用事件 handler 列表里的 handler
-func (ac *AcFunLive) callEvent(t eventType, i any) {
+func (ac *AcFunLive) callEvent(t EventType, i any) {
+	e := Event{Type: t, Data: i}
+	if !ac.applyEventMiddleware(&e) {
+		return
+	}
+
+	switch t {
+	case EventGift:
+		ac.addGiftCombo(e.Data.(*Gift), true)
+	case EventDanmuStop:
+		ac.flushGiftCombos(true)
+	}
+
+	ordered := ac.dispatchMode == DispatchOrdered
+	if ordered {
+		// 礼物连击结束的事件在单独的 goroutine 里分发，需要加锁保证序号和 handler 的调用顺序一致，并且 handler 不会被同时调用
+		ac.orderedMu.Lock()
+		defer ac.orderedMu.Unlock()
+	}
+	e.Seq = ac.eventSeq.Inc()
+
+	if s := ac.stream.Load(); s != nil {
+		s.send(e)
+	}
+
+	// 不持有锁调用 handler，handler 里可以注册新的 handler
 	ac.handlerMap.RLock()
-	defer ac.handlerMap.RUnlock()
-	list, ok := ac.handlerMap.listMap[t]
-	if ok {
-		for _, f := range list {
-			go func(f eventHandler) {
-				defer func() {
-					if err := recover(); err != nil {
-						log.Printf("dispatchEvent() %v goroutine error: %v", t, err)
-					}
-				}()
-				f(ac, i)
-			}(f)
+	list := ac.handlerMap.listMap[t]
+	all := ac.handlerMap.listMap[anyEvent]
+	ac.handlerMap.RUnlock()
+
+	for _, h := range list {
+		if ordered {
+			ac.callHandler(h.f, t, e.Data)
+		} else {
+			go ac.callHandler(h.f, t, e.Data)
+		}
+	}
+	for _, h := range all {
+		if ordered {
+			ac.callHandler(h.f, t, e)
+		} else {
+			go ac.callHandler(h.f, t, e)
 		}
 	}
 }
 
-// OnDanmuStop 处理获取弹幕结束，有可能是网络原因导致连接超时无法获取弹幕，直播不一定结束，可以多次调用
-func (ac *AcFunLive) OnDanmuStop(handler func(*AcFunLive, error)) {
-	ac.handlerMap.add(stopDanmu, func(ac *AcFunLive, i any) {
+// 调用事件 handler，handler 出现 panic 时记录日志
+func (ac *AcFunLive) callHandler(f eventHandler, t EventType, i any) {
+	defer func() {
+		if err := recover(); err != nil {
+			ac.t.logger().Error("dispatchEvent() error", "event", t, "error", err)
+		}
+	}()
+	f(ac, i)
+}
+
+// OnEvent 处理所有事件，e.Seq 为事件的序号，可以用来排序或检查事件是否遗漏。
+// 同一个事件会先调用对应的 On 开头的方法注册的 handler，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnEvent(handler func(*AcFunLive, Event)) func() {
+	return ac.handlerMap.add(anyEvent, func(ac *AcFunLive, i any) {
+		handler(ac, i.(Event))
+	})
+}
+
+// OnDanmuStop 处理获取弹幕结束，有可能是网络原因导致连接超时无法获取弹幕，直播不一定结束，可以多次调用，调用返回的函数可以取消注册。
+// 主播下播时 err 为 ErrLiveClosed，ctx 结束时 err 为 nil，其他原因可以用 errors.Is 判断，如 ErrLiveBanned、ErrKickedOut、ErrTokenExpired 和 ErrUnregistered。
+func (ac *AcFunLive) OnDanmuStop(handler func(*AcFunLive, error)) func() {
+	return ac.handlerMap.add(EventDanmuStop, func(ac *AcFunLive, i any) {
 		if i == nil {
 			handler(ac, nil)
 		} else {
@@ -84,184 +365,346 @@ func (ac *AcFunLive) OnDanmuStop(handler func(*AcFunLive, error)) {
 	})
 }
 
-// OnComment 处理评论弹幕，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnComment(handler func(*AcFunLive, *Comment)) {
-	ac.handlerMap.add(commentDanmu, func(ac *AcFunLive, i any) {
+// OnComment 处理评论弹幕，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnComment(handler func(*AcFunLive, *Comment)) func() {
+	return ac.handlerMap.add(EventComment, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*Comment))
 	})
 }
 
-// OnLike 处理点赞弹幕，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnLike(handler func(*AcFunLive, *Like)) {
-	ac.handlerMap.add(likeDanmu, func(ac *AcFunLive, i any) {
+// OnLike 处理点赞弹幕，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnLike(handler func(*AcFunLive, *Like)) func() {
+	return ac.handlerMap.add(EventLike, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*Like))
 	})
 }
 
-// OnEnterRoom 处理用户进场，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnEnterRoom(handler func(*AcFunLive, *EnterRoom)) {
-	ac.handlerMap.add(enterRoomDanmu, func(ac *AcFunLive, i any) {
+// OnEnterRoom 处理用户进场，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnEnterRoom(handler func(*AcFunLive, *EnterRoom)) func() {
+	return ac.handlerMap.add(EventEnterRoom, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*EnterRoom))
 	})
 }
 
-// OnFollowAuthor 处理用户关注主播，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnFollowAuthor(handler func(*AcFunLive, *FollowAuthor)) {
-	ac.handlerMap.add(followAuthorDanmu, func(ac *AcFunLive, i any) {
+// OnFollowAuthor 处理用户关注主播，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnFollowAuthor(handler func(*AcFunLive, *FollowAuthor)) func() {
+	return ac.handlerMap.add(EventFollowAuthor, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*FollowAuthor))
 	})
 }
 
-// OnThrowBanana 处理用户投蕉，现在基本用 OnGift 代替，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnThrowBanana(handler func(*AcFunLive, *ThrowBanana)) {
-	ac.handlerMap.add(throwBananaDanmu, func(ac *AcFunLive, i any) {
+// OnThrowBanana 处理用户投蕉，现在基本用 OnGift 代替，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnThrowBanana(handler func(*AcFunLive, *ThrowBanana)) func() {
+	return ac.handlerMap.add(EventThrowBanana, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*ThrowBanana))
 	})
 }
 
-// OnGift 处理用户赠送礼物，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnGift(handler func(*AcFunLive, *Gift)) {
-	ac.handlerMap.add(giftDanmu, func(ac *AcFunLive, i any) {
+// OnGift 处理用户赠送礼物，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnGift(handler func(*AcFunLive, *Gift)) func() {
+	return ac.handlerMap.add(EventGift, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*Gift))
 	})
 }
 
-// OnRichText 处理富文本，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnRichText(handler func(*AcFunLive, *RichText)) {
-	ac.handlerMap.add(richTextDanmu, func(ac *AcFunLive, i any) {
+// OnGiftComboEnd 处理礼物连击结束，同一个 ComboID 的礼物在 SlotDisplayDuration 内没有再次赠送或者弹幕获取结束时调用，
+// 可以用来统计礼物总数和总价值，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnGiftComboEnd(handler func(*AcFunLive, *GiftComboEnd)) func() {
+	return ac.handlerMap.add(EventGiftComboEnd, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*GiftComboEnd))
+	})
+}
+
+// OnRichText 处理富文本，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnRichText(handler func(*AcFunLive, *RichText)) func() {
+	return ac.handlerMap.add(EventRichText, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*RichText))
 	})
 }
 
-// OnJoinClub 处理用户加入主播守护团，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnJoinClub(handler func(*AcFunLive, *JoinClub)) {
-	ac.handlerMap.add(joinClubDanmu, func(ac *AcFunLive, i any) {
+// OnJoinClub 处理用户加入主播守护团，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnJoinClub(handler func(*AcFunLive, *JoinClub)) func() {
+	return ac.handlerMap.add(EventJoinClub, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*JoinClub))
 	})
 }
 
-// OnShareLive 处理分享直播间到其他平台的弹幕，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnShareLive(handler func(*AcFunLive, *ShareLive)) {
-	ac.handlerMap.add(shareLiveDanmu, func(ac *AcFunLive, i any) {
+// OnShareLive 处理分享直播间到其他平台的弹幕，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnShareLive(handler func(*AcFunLive, *ShareLive)) func() {
+	return ac.handlerMap.add(EventShareLive, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*ShareLive))
 	})
 }
 
-// OnBananaCount 处理直播间获得的香蕉数，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnBananaCount(handler func(ac *AcFunLive, allBananaCount string)) {
-	ac.handlerMap.add(bananaCountEvent, func(ac *AcFunLive, i any) {
+// OnBananaCount 处理直播间获得的香蕉数，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnBananaCount(handler func(ac *AcFunLive, allBananaCount string)) func() {
+	return ac.handlerMap.add(EventBananaCount, func(ac *AcFunLive, i any) {
 		handler(ac, i.(string))
 	})
 }
 
-// OnDisplayInfo 处理直播间的一些数据，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnDisplayInfo(handler func(*AcFunLive, *DisplayInfo)) {
-	ac.handlerMap.add(displayEvent, func(ac *AcFunLive, i any) {
+// OnDisplayInfo 处理直播间的一些数据，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnDisplayInfo(handler func(*AcFunLive, *DisplayInfo)) func() {
+	return ac.handlerMap.add(EventDisplayInfo, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*DisplayInfo))
 	})
 }
 
-// OnTopUsers 处理直播间礼物榜在线前三的信息，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnTopUsers(handler func(*AcFunLive, []TopUser)) {
-	ac.handlerMap.add(topUsersEvent, func(ac *AcFunLive, i any) {
+// OnTopUsers 处理直播间礼物榜在线前三的信息，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnTopUsers(handler func(*AcFunLive, []TopUser)) func() {
+	return ac.handlerMap.add(EventTopUsers, func(ac *AcFunLive, i any) {
 		handler(ac, i.([]TopUser))
 	})
 }
 
-// OnRecentComment 处理 APP 进直播间时显示的最近发的弹幕，可以多次调用
-func (ac *AcFunLive) OnRecentComment(handler func(*AcFunLive, []Comment)) {
-	ac.handlerMap.add(recentCommentEvent, func(ac *AcFunLive, i any) {
+// OnRecentComment 处理 APP 进直播间时显示的最近发的弹幕，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnRecentComment(handler func(*AcFunLive, []Comment)) func() {
+	return ac.handlerMap.add(EventRecentComment, func(ac *AcFunLive, i any) {
 		handler(ac, i.([]Comment))
 	})
 }
 
-// OnChatCall 处理主播发起连麦，可以多次调用
-func (ac *AcFunLive) OnChatCall(handler func(*AcFunLive, *ChatCall)) {
-	ac.handlerMap.add(chatCallEvent, func(ac *AcFunLive, i any) {
+// OnChatCall 处理主播发起连麦，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnChatCall(handler func(*AcFunLive, *ChatCall)) func() {
+	return ac.handlerMap.add(EventChatCall, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*ChatCall))
 	})
 }
 
-// OnChatAccept 处理用户接受连麦，可以多次调用
-func (ac *AcFunLive) OnChatAccept(handler func(*AcFunLive, *ChatAccept)) {
-	ac.handlerMap.add(chatAcceptEvent, func(ac *AcFunLive, i any) {
+// OnChatAccept 处理用户接受连麦，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnChatAccept(handler func(*AcFunLive, *ChatAccept)) func() {
+	return ac.handlerMap.add(EventChatAccept, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*ChatAccept))
 	})
 }
 
-// OnChatReady 处理用户接受连麦的信息，可以多次调用
-func (ac *AcFunLive) OnChatReady(handler func(*AcFunLive, *ChatReady)) {
-	ac.handlerMap.add(chatReadyEvent, func(ac *AcFunLive, i any) {
+// OnChatReady 处理用户接受连麦的信息，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnChatReady(handler func(*AcFunLive, *ChatReady)) func() {
+	return ac.handlerMap.add(EventChatReady, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*ChatReady))
 	})
 }
 
-// OnChatEnd 处理连麦结束，可以多次调用
-func (ac *AcFunLive) OnChatEnd(handler func(*AcFunLive, *ChatEnd)) {
-	ac.handlerMap.add(chatEndEvent, func(ac *AcFunLive, i any) {
+// OnChatEnd 处理连麦结束，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnChatEnd(handler func(*AcFunLive, *ChatEnd)) func() {
+	return ac.handlerMap.add(EventChatEnd, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*ChatEnd))
 	})
 }
 
-// OnAuthorChatCall 处理主播发起连麦，可以多次调用
-func (ac *AcFunLive) OnAuthorChatCall(handler func(*AcFunLive, *AuthorChatCall)) {
-	ac.handlerMap.add(authorChatCallEvent, func(ac *AcFunLive, i any) {
+// OnAuthorChatCall 处理主播发起连麦，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnAuthorChatCall(handler func(*AcFunLive, *AuthorChatCall)) func() {
+	return ac.handlerMap.add(EventAuthorChatCall, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*AuthorChatCall))
 	})
 }
 
-// OnAuthorChatAccept 处理主播接受连麦，可以多次调用
-func (ac *AcFunLive) OnAuthorChatAccept(handler func(*AcFunLive, *AuthorChatAccept)) {
-	ac.handlerMap.add(authorChatAcceptEvent, func(ac *AcFunLive, i any) {
+// OnAuthorChatAccept 处理主播接受连麦，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnAuthorChatAccept(handler func(*AcFunLive, *AuthorChatAccept)) func() {
+	return ac.handlerMap.add(EventAuthorChatAccept, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*AuthorChatAccept))
 	})
 }
 
-// OnAuthorChatReady 处理主播接受连麦的信息，可以多次调用
-func (ac *AcFunLive) OnAuthorChatReady(handler func(*AcFunLive, *AuthorChatReady)) {
-	ac.handlerMap.add(authorChatReadyEvent, func(ac *AcFunLive, i any) {
+// OnAuthorChatReady 处理主播接受连麦的信息，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnAuthorChatReady(handler func(*AcFunLive, *AuthorChatReady)) func() {
+	return ac.handlerMap.add(EventAuthorChatReady, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*AuthorChatReady))
 	})
 }
 
-// OnAuthorChatEnd 处理连麦结束，可以多次调用
-func (ac *AcFunLive) OnAuthorChatEnd(handler func(*AcFunLive, *AuthorChatEnd)) {
-	ac.handlerMap.add(authorChatEndEvent, func(ac *AcFunLive, i any) {
+// OnAuthorChatEnd 处理连麦结束，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnAuthorChatEnd(handler func(*AcFunLive, *AuthorChatEnd)) func() {
+	return ac.handlerMap.add(EventAuthorChatEnd, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*AuthorChatEnd))
 	})
 }
 
-// OnAuthorChatChangeSoundConfig 处理主播连麦声音设置更改，可以多次调用
-func (ac *AcFunLive) OnAuthorChatChangeSoundConfig(handler func(*AcFunLive, *AuthorChatChangeSoundConfig)) {
-	ac.handlerMap.add(authorChatChangeSoundConfigEvent, func(ac *AcFunLive, i any) {
+// OnAuthorChatChangeSoundConfig 处理主播连麦声音设置更改，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnAuthorChatChangeSoundConfig(handler func(*AcFunLive, *AuthorChatChangeSoundConfig)) func() {
+	return ac.handlerMap.add(EventAuthorChatChangeSoundConfig, func(ac *AcFunLive, i any) {
 		handler(ac, i.(*AuthorChatChangeSoundConfig))
 	})
 }
 
-// OnRedpackList 处理直播间的红包列表，handler 需要支持并行处理，可以多次调用
-func (ac *AcFunLive) OnRedpackList(handler func(*AcFunLive, []Redpack)) {
-	ac.handlerMap.add(redpackListEvent, func(ac *AcFunLive, i any) {
+// OnPKInvitation 处理主播发起 PK，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnPKInvitation(handler func(*AcFunLive, *PKInvitation)) func() {
+	return ac.handlerMap.add(EventPKInvitation, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*PKInvitation))
+	})
+}
+
+// OnPKAccept 处理主播接受 PK，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnPKAccept(handler func(*AcFunLive, *PKAccept)) func() {
+	return ac.handlerMap.add(EventPKAccept, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*PKAccept))
+	})
+}
+
+// OnPKReady 处理 PK 准备开始，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnPKReady(handler func(*AcFunLive, *PKReady)) func() {
+	return ac.handlerMap.add(EventPKReady, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*PKReady))
+	})
+}
+
+// OnPKStatistic 处理 PK 的统计数据（得分、回合和观众助攻榜），handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnPKStatistic(handler func(*AcFunLive, *PKStatistic)) func() {
+	return ac.handlerMap.add(EventPKStatistic, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*PKStatistic))
+	})
+}
+
+// OnPKEnd 处理 PK 结束，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnPKEnd(handler func(*AcFunLive, *PKEnd)) func() {
+	return ac.handlerMap.add(EventPKEnd, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*PKEnd))
+	})
+}
+
+// OnPKSoundConfigChanged 处理 PK 时主播更改声音设置，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnPKSoundConfigChanged(handler func(*AcFunLive, *PKSoundConfigChanged)) func() {
+	return ac.handlerMap.add(EventPKSoundConfigChanged, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*PKSoundConfigChanged))
+	})
+}
+
+// OnRedpackList 处理直播间的红包列表，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnRedpackList(handler func(*AcFunLive, []Redpack)) func() {
+	return ac.handlerMap.add(EventRedpackList, func(ac *AcFunLive, i any) {
 		handler(ac, i.([]Redpack))
 	})
 }
 
-// OnKickedOut 处理被踢出直播间，可以多次调用
-func (ac *AcFunLive) OnKickedOut(handler func(ac *AcFunLive, kickedOutReason string)) {
-	ac.handlerMap.add(kickedOutEvent, func(ac *AcFunLive, i any) {
+// OnLiveStatusChanged 处理直播状态变化，主播下播或直播间被封禁后会停止获取弹幕，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnLiveStatusChanged(handler func(*AcFunLive, *LiveStatusChange)) func() {
+	return ac.handlerMap.add(EventLiveStatusChanged, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*LiveStatusChange))
+	})
+}
+
+// OnAuthorPause 处理主播暂停直播，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnAuthorPause(handler func(*AcFunLive, *AuthorPause)) func() {
+	return ac.handlerMap.add(EventAuthorPause, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*AuthorPause))
+	})
+}
+
+// OnAuthorResume 处理主播恢复直播，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnAuthorResume(handler func(*AcFunLive, *AuthorResume)) func() {
+	return ac.handlerMap.add(EventAuthorResume, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*AuthorResume))
+	})
+}
+
+// OnWidget 处理直播间挂件，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnWidget(handler func(*AcFunLive, []Widget)) func() {
+	return ac.handlerMap.add(EventWidget, func(ac *AcFunLive, i any) {
+		handler(ac, i.([]Widget))
+	})
+}
+
+// OnWishSheet 处理主播心愿单的进度，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnWishSheet(handler func(*AcFunLive, *WishSheet)) func() {
+	return ac.handlerMap.add(EventWishSheet, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*WishSheet))
+	})
+}
+
+// OnTopBanner 处理直播间顶部的横幅公告，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnTopBanner(handler func(*AcFunLive, *TopBanner)) func() {
+	return ac.handlerMap.add(EventTopBanner, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*TopBanner))
+	})
+}
+
+// OnShoppingCart 处理直播间购物车状态，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnShoppingCart(handler func(*AcFunLive, *ShoppingCart)) func() {
+	return ac.handlerMap.add(EventShoppingCart, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*ShoppingCart))
+	})
+}
+
+// OnEcommerceCart 处理直播间电商购物车状态，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnEcommerceCart(handler func(*AcFunLive, *EcommerceCart)) func() {
+	return ac.handlerMap.add(EventEcommerceCart, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*EcommerceCart))
+	})
+}
+
+// OnEcommerceCartItemPopup 处理直播间电商购物车弹出的商品，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnEcommerceCartItemPopup(handler func(*AcFunLive, *EcommerceCartItemPopup)) func() {
+	return ac.handlerMap.add(EventEcommerceCartItemPopup, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*EcommerceCartItemPopup))
+	})
+}
+
+// OnNewApplyUser 处理观众申请连麦，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnNewApplyUser(handler func(*AcFunLive, *ApplyUser)) func() {
+	return ac.handlerMap.add(EventNewApplyUser, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*ApplyUser))
+	})
+}
+
+// OnRemoveApplyUser 处理观众离开连麦申请队列，handler 需要支持并行处理，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnRemoveApplyUser(handler func(*AcFunLive, *RemoveApplyUser)) func() {
+	return ac.handlerMap.add(EventRemoveApplyUser, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*RemoveApplyUser))
+	})
+}
+
+// OnFeatureStateSync 处理直播间功能的开关状态，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnFeatureStateSync(handler func(*AcFunLive, []LiveFeature)) func() {
+	return ac.handlerMap.add(EventFeatureStateSync, func(ac *AcFunLive, i any) {
+		handler(ac, i.([]LiveFeature))
+	})
+}
+
+// OnCoverAuditResult 处理直播间封面和标题的审核结果，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnCoverAuditResult(handler func(*AcFunLive, CoverAuditResultType)) func() {
+	return ac.handlerMap.add(EventCoverAuditResult, func(ac *AcFunLive, i any) {
+		handler(ac, i.(CoverAuditResultType))
+	})
+}
+
+// OnUnknownSignal 处理未知的弹幕数据，非事件响应模式下也会调用，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnUnknownSignal(handler func(*AcFunLive, UnknownSignal)) func() {
+	return ac.handlerMap.add(EventUnknownSignal, func(ac *AcFunLive, i any) {
+		handler(ac, i.(UnknownSignal))
+	})
+}
+
+// OnKickedOut 处理被踢出直播间，之后弹幕获取会以 ErrKickedOut 结束，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnKickedOut(handler func(ac *AcFunLive, kickedOutReason string)) func() {
+	return ac.handlerMap.add(EventKickedOut, func(ac *AcFunLive, i any) {
 		handler(ac, i.(string))
 	})
 }
 
-// OnViolationAlert 处理直播间警告，可以多次调用
-func (ac *AcFunLive) OnViolationAlert(handler func(ac *AcFunLive, violationContent string)) {
-	ac.handlerMap.add(violationAlertEvent, func(ac *AcFunLive, i any) {
+// OnViolationAlert 处理直播间警告，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnViolationAlert(handler func(ac *AcFunLive, violationContent string)) func() {
+	return ac.handlerMap.add(EventViolationAlert, func(ac *AcFunLive, i any) {
 		handler(ac, i.(string))
 	})
 }
 
-// OnManagerState 处理登陆帐号的房管状态，可以多次调用
-func (ac *AcFunLive) OnManagerState(handler func(*AcFunLive, ManagerState)) {
-	ac.handlerMap.add(managerStateEvent, func(ac *AcFunLive, i any) {
+// OnManagerState 处理登陆帐号的房管状态，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnManagerState(handler func(*AcFunLive, ManagerState)) func() {
+	return ac.handlerMap.add(EventManagerState, func(ac *AcFunLive, i any) {
 		handler(ac, i.(ManagerState))
 	})
 }
+
+// OnReconnecting 处理弹幕连接断开后准备重连，需要用 SetReconnectPolicy() 设置重连策略，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnReconnecting(handler func(*AcFunLive, *Reconnecting)) func() {
+	return ac.handlerMap.add(EventReconnecting, func(ac *AcFunLive, i any) {
+		handler(ac, i.(*Reconnecting))
+	})
+}
+
+// OnReconnected 处理重连成功并重新进入直播间，attempts 为这次重连尝试的次数，可以多次调用，调用返回的函数可以取消注册
+func (ac *AcFunLive) OnReconnected(handler func(ac *AcFunLive, attempts int)) func() {
+	return ac.handlerMap.add(EventReconnected, func(ac *AcFunLive, i any) {
+		handler(ac, i.(int))
+	})
+}
@@ -1,8 +1,12 @@
 package acfundanmu
 
 import (
+	"fmt"
+	"log/slog"
+	"net/url"
 	"sync"
 
+	"github.com/valyala/fasthttp"
 	"github.com/valyala/fastjson"
 	"go.uber.org/atomic"
 )
@@ -115,6 +119,38 @@ type token struct {
 	liverUID        int64 // 主播 uid
 	livePage        string
 	err             *atomic.Error
+	closed          *atomic.Bool                // 是否主动关闭了弹幕连接
+	log             *slog.Logger                // 日志，为 nil 时使用 slog.Default()
+	logCache        atomic.Pointer[tokenLogger] // 带有主播 uid 和 liveID 的日志
+	proxy           *url.URL                    // 代理，为 nil 时使用全局代理
+	client          *fasthttp.Client            // HTTP 客户端，为 nil 时使用 defaultClient
+	endpoints       *endpointMap                // 替换 API 的 host，为 nil 时使用全局的设置
+}
+
+// 返回只有设备 ID 的 token，用于不需要 AcFunLive 的函数
+func newDeviceToken(deviceID string) *token {
+	return &token{TokenInfo: TokenInfo{DeviceID: deviceID}}
+}
+
+// 换用下一个 ticket
+func (t *token) nextTicket() {
+	if len(t.tickets) == 0 {
+		return
+	}
+	index := t.ticketIndex.Load()
+	_ = t.ticketIndex.CompareAndSwap(index, (index+1)%uint32(len(t.tickets)))
+}
+
+// 重连弹幕服务器前重置连接相关的状态，并换用下一个 ticket
+func (t *token) resetConnection() {
+	t.instanceID = 0
+	t.sessionKey = nil
+	t.seqID.Store(1)
+	t.headerSeqID.Store(1)
+	t.heartbeatSeqID = 0
+	t.err.Store(nil)
+	t.closed.Store(false)
+	t.nextTicket()
 }
 
 // 检查错误
@@ -123,3 +159,12 @@ func checkErr(err error) {
 		panic(err)
 	}
 }
+
+// 将 recover() 返回的值转换为 error，以便用 errors.Is/As 判断原来的错误
+func recoverErr(r any) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+
+	return fmt.Errorf("%v", r)
+}
@@ -4,7 +4,6 @@ go 1.23
 
 require (
 	facette.io/natsort v0.0.0-20181210072756-2cd4dd1e2dcb
-	github.com/Workiva/go-datastructures v1.1.5
 	github.com/orzogc/fastws v1.0.5-0.20230809182400-6c9094d8c52e
 	github.com/segmentio/encoding v0.4.0
 	github.com/valyala/fasthttp v1.55.0
@@ -1,14 +1,12 @@
 package acfundanmu
 
 import (
-	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"fmt"
-	"io"
-	"log"
+	"slices"
 	"sort"
+	"time"
 
 	"github.com/orzogc/acfundanmu/acproto"
 
@@ -19,7 +17,7 @@ import (
 func (ac *AcFunLive) handleCommand(ctx context.Context, stream *acproto.DownstreamPayload, event bool) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("handleCommand() error: %v", err)
+			e = fmt.Errorf("handleCommand() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -38,23 +36,26 @@ func (ac *AcFunLive) handleCommand(ctx context.Context, stream *acproto.Downstre
 			err = proto.Unmarshal(cmd.Payload, enterRoom)
 			checkErr(err)
 			if enterRoom.HeartbeatIntervalMs > 0 {
-				go ac.clientHeartbeat(ctx, enterRoom.HeartbeatIntervalMs)
+				ac.startHeartbeat(ctx, enterRoom.HeartbeatIntervalMs)
 			} else {
-				go ac.clientHeartbeat(ctx, 10000)
+				ac.startHeartbeat(ctx, defaultHeartbeatInterval)
+			}
+			if attempts := ac.attempt.Swap(0); attempts > 0 {
+				ac.t.logger().Info("重连直播间成功", "attempts", attempts)
+				ac.emitEvent(event, EventReconnected, int(attempts))
 			}
 		case "ZtLiveCsHeartbeatAck":
 			//heartbeat := &acproto.ZtLiveCsHeartbeatAck{}
 			//err = proto.Unmarshal(cmd.Payload, heartbeat)
 			//checkErr(err)
+			_ = ac.stats.heartbeatsAcked.Inc()
+			ac.lastAlive.Store(time.Now().UnixNano())
 		case "ZtLiveCsUserExitAck":
 			//userExit := &acproto.ZtLiveCsUserExitAck{}
 			//err = proto.Unmarshal(cmd.Payload, userExit)
 			//checkErr(err)
 		default:
-			log.Printf("未知的cmd.CmdAckType：%s\npayload string:\n%s\npayload base64:\n%s\n",
-				cmd.CmdAckType,
-				string(cmd.Payload),
-				base64.StdEncoding.EncodeToString(cmd.Payload))
+			ac.handleUnknownSignal(SignalLayerCmdAck, cmd.CmdAckType, cmd.Payload)
 		}
 	case "Basic.Handshake":
 		handshake := &acproto.HansshakeResponse{}
@@ -69,38 +70,52 @@ func (ac *AcFunLive) handleCommand(ctx context.Context, stream *acproto.Downstre
 		checkErr(err)
 		ac.t.instanceID = register.InstanceId
 		ac.t.sessionKey = register.SessKey
+		if ac.danmuClient.Type() == WebSocketDanmuClientType {
+			ac.updateAccessPoints(register.AccessPointsConfigWs, register.CleanAccessPoint)
+		} else {
+			ac.updateAccessPoints(register.AccessPointsConfig, register.CleanAccessPoint)
+		}
 
 		_, err = ac.danmuClient.Write(ac.t.keepAlive())
 		checkErr(err)
+		ac.stats.pingSending()
+		_, err = ac.danmuClient.Write(ac.t.ping())
+		checkErr(err)
 		_, err = ac.danmuClient.Write(ac.t.enterRoom())
 		checkErr(err)
 	case "Basic.KeepAlive":
-		//keepalive := &acproto.KeepAliveResponse{}
-		//err := proto.Unmarshal(stream.PayloadData, keepalive)
-		//checkErr(err)
+		keepAlive := &acproto.KeepAliveResponse{}
+		err := proto.Unmarshal(stream.PayloadData, keepAlive)
+		checkErr(err)
+		if ac.danmuClient.Type() == WebSocketDanmuClientType {
+			ac.updateAccessPoints(keepAlive.AccessPointsConfigWs, false)
+		} else {
+			ac.updateAccessPoints(keepAlive.AccessPointsConfig, false)
+		}
 	case "Basic.Ping":
 		//ping := &acproto.PingResponse{}
 		//err := proto.Unmarshal(stream.PayloadData, ping)
 		//checkErr(err)
+		ac.stats.pongReceived()
 	case "Basic.Unregister":
 		unregister := &acproto.UnregisterResponse{}
 		err := proto.Unmarshal(stream.PayloadData, unregister)
 		checkErr(err)
-		ac.t.err.Store(fmt.Errorf("接收到 Unregister 信号"))
+		ac.t.err.Store(ErrUnregistered)
 		_ = ac.danmuClient.Close("Unregister")
 	case "Push.ZtLiveInteractive.Message":
 		_, err := ac.danmuClient.Write(ac.t.pushMessage())
 		checkErr(err)
-		message := &acproto.ZtLiveScMessage{}
+		message := scMessagePool.get()
+		defer scMessagePool.put(message)
 		err = proto.Unmarshal(stream.PayloadData, message)
 		checkErr(err)
 		payload := message.Payload
 		if message.CompressionType == acproto.ZtLiveScMessage_GZIP {
-			r, err := gzip.NewReader(bytes.NewReader(message.Payload))
-			checkErr(err)
-			defer r.Close()
-			payload, err = io.ReadAll(r)
+			d, data, err := gunzip(message.Payload)
 			checkErr(err)
+			defer releaseGzip(d)
+			payload = data
 		}
 		switch message.MessageType {
 		case "ZtLiveScActionSignal":
@@ -113,22 +128,32 @@ func (ac *AcFunLive) handleCommand(ctx context.Context, stream *acproto.Downstre
 			statusChanged := &acproto.ZtLiveScStatusChanged{}
 			err = proto.Unmarshal(payload, statusChanged)
 			checkErr(err)
-			if statusChanged.Type == acproto.ZtLiveScStatusChanged_LIVE_CLOSED || statusChanged.Type == acproto.ZtLiveScStatusChanged_LIVE_BANNED {
+			change := &LiveStatusChange{
+				Type:           LiveStatusType(statusChanged.Type),
+				MaxRandomDelay: statusChanged.MaxRandomDelayMs,
+			}
+			if statusChanged.BannedInfo != nil {
+				change.BanReason = statusChanged.BannedInfo.BanReason
+			}
+			ac.emitEvent(event, EventLiveStatusChanged, change)
+			switch statusChanged.Type {
+			case acproto.ZtLiveScStatusChanged_LIVE_CLOSED:
+				ac.t.err.Store(ErrLiveClosed)
 				ac.clientStop("Live closed")
+			case acproto.ZtLiveScStatusChanged_LIVE_BANNED:
+				ac.t.err.Store(fmt.Errorf("%w：%s", ErrLiveBanned, change.BanReason))
+				ac.clientStop("Live banned")
 			}
 		case "ZtLiveScTicketInvalid":
 			ticketInvalid := &acproto.ZtLiveScTicketInvalid{}
 			err = proto.Unmarshal(payload, ticketInvalid)
 			checkErr(err)
-			index := ac.t.ticketIndex.Load()
-			_ = ac.t.ticketIndex.CompareAndSwap(index, (index+1)%uint32(len(ac.t.tickets)))
+			ac.t.nextTicket()
+			_ = ac.stats.ticketRotations.Inc()
 			_, err = ac.danmuClient.Write(ac.t.enterRoom())
 			checkErr(err)
 		default:
-			log.Printf("未知的message.MessageType：%s\npayload string:\n%s\npayload base64:\n%s\n",
-				message.MessageType,
-				string(payload),
-				base64.StdEncoding.EncodeToString(payload))
+			ac.handleUnknownSignal(SignalLayerMessage, message.MessageType, payload)
 		}
 	// AcFun 帐号收到的私信信息
 	case "Push.Message":
@@ -137,18 +162,15 @@ func (ac *AcFunLive) handleCommand(ctx context.Context, stream *acproto.Downstre
 	case "Push.acfun":
 	default:
 		if stream.ErrorCode > 0 {
-			log.Println("DownstreamPayload error:", stream.ErrorCode, stream.ErrorMsg)
-			if stream.ErrorCode == 10018 {
-				ac.t.err.Store(fmt.Errorf(string(stream.ErrorData)))
+			ac.t.logger().Error("DownstreamPayload error", "command", stream.Command, "code", stream.ErrorCode, "message", stream.ErrorMsg)
+			if stream.ErrorCode == tokenExpiredCode {
+				ac.t.err.Store(fmt.Errorf("%w：%s", ErrTokenExpired, string(stream.ErrorData)))
 				ac.clientStop("Log out")
 			} else {
-				log.Printf("接收弹幕出现错误：%s", string(stream.ErrorData))
+				ac.t.logger().Error("接收弹幕出现错误", "command", stream.Command, "data", string(stream.ErrorData))
 			}
 		} else {
-			log.Printf("未知的stream.Command：%s\npayload string:\n%s\npayload base64:\n%s\n",
-				stream.Command,
-				string(stream.PayloadData),
-				base64.StdEncoding.EncodeToString(stream.PayloadData))
+			ac.handleUnknownSignal(SignalLayerCommand, stream.Command, stream.PayloadData)
 		}
 	}
 
@@ -157,7 +179,8 @@ func (ac *AcFunLive) handleCommand(ctx context.Context, stream *acproto.Downstre
 
 // 处理 action signal 数据
 func (ac *AcFunLive) handleActionSignal(payload *[]byte, event bool) {
-	actionSignal := &acproto.ZtLiveScActionSignal{}
+	actionSignal := actionPool.get()
+	defer actionPool.put(actionSignal)
 	err := proto.Unmarshal(*payload, actionSignal)
 	checkErr(err)
 
@@ -166,7 +189,7 @@ func (ac *AcFunLive) handleActionSignal(payload *[]byte, event bool) {
 		for _, pl := range item.Payload {
 			switch item.SignalType {
 			case "CommonActionSignalComment":
-				comment := &acproto.CommonActionSignalComment{}
+				comment := commentPool.get()
 				err = proto.Unmarshal(pl, comment)
 				checkErr(err)
 				d := &Comment{
@@ -176,33 +199,37 @@ func (ac *AcFunLive) handleActionSignal(payload *[]byte, event bool) {
 					},
 					Content: comment.Content,
 				}
+				commentPool.put(comment)
 				danmu = append(danmu, d)
 			case "CommonActionSignalLike":
-				like := &acproto.CommonActionSignalLike{}
+				like := likePool.get()
 				err = proto.Unmarshal(pl, like)
 				checkErr(err)
 				d := &Like{
 					SendTime: like.SendTimeMs,
 					UserInfo: *NewUserInfo(like.UserInfo),
 				}
+				likePool.put(like)
 				danmu = append(danmu, d)
 			case "CommonActionSignalUserEnterRoom":
-				enter := &acproto.CommonActionSignalUserEnterRoom{}
+				enter := enterRoomPool.get()
 				err = proto.Unmarshal(pl, enter)
 				checkErr(err)
 				d := &EnterRoom{
 					SendTime: enter.SendTimeMs,
 					UserInfo: *NewUserInfo(enter.UserInfo),
 				}
+				enterRoomPool.put(enter)
 				danmu = append(danmu, d)
 			case "CommonActionSignalUserFollowAuthor":
-				follow := &acproto.CommonActionSignalUserFollowAuthor{}
+				follow := followPool.get()
 				err = proto.Unmarshal(pl, follow)
 				checkErr(err)
 				d := &FollowAuthor{
 					SendTime: follow.SendTimeMs,
 					UserInfo: *NewUserInfo(follow.UserInfo),
 				}
+				followPool.put(follow)
 				danmu = append(danmu, d)
 			case "AcfunActionSignalThrowBanana":
 				banana := &acproto.AcfunActionSignalThrowBanana{}
@@ -220,7 +247,7 @@ func (ac *AcFunLive) handleActionSignal(payload *[]byte, event bool) {
 				}
 				danmu = append(danmu, d)
 			case "CommonActionSignalGift":
-				gift := &acproto.CommonActionSignalGift{}
+				gift := giftPool.get()
 				err = proto.Unmarshal(pl, gift)
 				checkErr(err)
 				ac.t.giftsMutex.RLock()
@@ -228,24 +255,23 @@ func (ac *AcFunLive) handleActionSignal(payload *[]byte, event bool) {
 				ac.t.giftsMutex.RUnlock()
 				// 存在未知礼物时
 				if !ok {
-					list, err := ac.t.getGiftList(ac.t.liveID)
-					if err != nil {
-						log.Printf("获取礼物列表出现错误：%v", err)
-						g = GiftDetail{
-							GiftID:   gift.GiftId,
-							GiftName: "未知礼物",
-						}
-					} else {
-						ac.t.giftsMutex.Lock()
-						ac.t.gifts = list
-						g, ok = ac.t.gifts[gift.GiftId]
-						if !ok {
-							g = GiftDetail{
-								GiftID:   gift.GiftId,
-								GiftName: "未知礼物",
+					g = GiftDetail{
+						GiftID:   gift.GiftId,
+						GiftName: "未知礼物",
+					}
+					// 回放时不联网，礼物详细信息以抓取文件里的礼物列表为准
+					if !ac.replay {
+						list, err := ac.t.getGiftList(context.Background(), ac.t.liveID)
+						if err != nil {
+							ac.t.logger().Error("获取礼物列表出现错误", "error", err)
+						} else {
+							ac.t.giftsMutex.Lock()
+							ac.t.gifts = list
+							if detail, ok := list[gift.GiftId]; ok {
+								g = detail
 							}
+							ac.t.giftsMutex.Unlock()
 						}
-						ac.t.giftsMutex.Unlock()
 					}
 				}
 				d := &Gift{
@@ -278,6 +304,7 @@ func (ac *AcFunLive) handleActionSignal(payload *[]byte, event bool) {
 						}
 					}
 				}
+				giftPool.put(gift)
 				danmu = append(danmu, d)
 			case "CommonActionSignalRichText":
 				richText := &acproto.CommonActionSignalRichText{}
@@ -312,7 +339,7 @@ func (ac *AcFunLive) handleActionSignal(payload *[]byte, event bool) {
 						}
 						d.Segments[i] = image
 					default:
-						log.Println("出现未处理的 RichText Segment")
+						ac.t.logger().Warn("出现未处理的 RichText Segment")
 					}
 				}
 				danmu = append(danmu, d)
@@ -346,10 +373,7 @@ func (ac *AcFunLive) handleActionSignal(payload *[]byte, event bool) {
 				}
 				danmu = append(danmu, d)
 			default:
-				log.Printf("未知的Action Signal item.SignalType：%s\npayload string:\n%s\npayload base64:\n%s\n",
-					item.SignalType,
-					string(pl),
-					base64.StdEncoding.EncodeToString(pl))
+				ac.handleUnknownSignal(SignalLayerAction, item.SignalType, pl)
 			}
 		}
 	}
@@ -360,33 +384,12 @@ func (ac *AcFunLive) handleActionSignal(payload *[]byte, event bool) {
 	})
 
 	for _, d := range danmu {
-		if event {
-			switch d := d.(type) {
-			case *Comment:
-				ac.callEvent(commentDanmu, d)
-			case *Like:
-				ac.callEvent(likeDanmu, d)
-			case *EnterRoom:
-				ac.callEvent(enterRoomDanmu, d)
-			case *FollowAuthor:
-				ac.callEvent(followAuthorDanmu, d)
-			case *ThrowBanana:
-				ac.callEvent(throwBananaDanmu, d)
-			case *Gift:
-				ac.callEvent(giftDanmu, d)
-			case *RichText:
-				ac.callEvent(richTextDanmu, d)
-			case *JoinClub:
-				ac.callEvent(joinClubDanmu, d)
-			case *ShareLive:
-				ac.callEvent(shareLiveDanmu, d)
-			default:
-				log.Println("出现未处理的 DanmuMessage")
-			}
-		} else {
-			err = ac.q.Put(d)
-			checkErr(err)
+		t, ok := danmuEventType(d)
+		if !ok {
+			ac.t.logger().Warn("出现未处理的 DanmuMessage", "type", fmt.Sprintf("%T", d))
+			continue
 		}
+		ac.emitEvent(event, t, d)
 	}
 }
 
@@ -402,13 +405,12 @@ func (ac *AcFunLive) handleStateSignal(payload *[]byte, event bool) {
 			bananaInfo := &acproto.AcfunStateSignalDisplayInfo{}
 			err = proto.Unmarshal(item.Payload, bananaInfo)
 			checkErr(err)
-			if event {
-				ac.callEvent(bananaCountEvent, bananaInfo.BananaCount)
-			} else {
+			if !event {
 				ac.info.Lock()
 				ac.info.AllBananaCount = bananaInfo.BananaCount
 				ac.info.Unlock()
 			}
+			ac.emitEvent(event, EventBananaCount, bananaInfo.BananaCount)
 		case "CommonStateSignalDisplayInfo":
 			stateInfo := &acproto.CommonStateSignalDisplayInfo{}
 			err = proto.Unmarshal(item.Payload, stateInfo)
@@ -418,13 +420,12 @@ func (ac *AcFunLive) handleStateSignal(payload *[]byte, event bool) {
 				LikeCount:     stateInfo.LikeCount,
 				LikeDelta:     int(stateInfo.LikeDelta),
 			}
-			if event {
-				ac.callEvent(displayEvent, &info)
-			} else {
+			if !event {
 				ac.info.Lock()
 				ac.info.DisplayInfo = info
 				ac.info.Unlock()
 			}
+			ac.emitEvent(event, EventDisplayInfo, &info)
 		case "CommonStateSignalTopUsers":
 			topUsers := &acproto.CommonStateSignalTopUsers{}
 			err = proto.Unmarshal(item.Payload, topUsers)
@@ -439,13 +440,12 @@ func (ac *AcFunLive) handleStateSignal(payload *[]byte, event bool) {
 				}
 				users[i] = u
 			}
-			if event {
-				ac.callEvent(topUsersEvent, users)
-			} else {
+			if !event {
 				ac.info.Lock()
 				ac.info.TopUsers = users
 				ac.info.Unlock()
 			}
+			ac.emitEvent(event, EventTopUsers, users)
 		case "CommonStateSignalRecentComment":
 			comments := &acproto.CommonStateSignalRecentComment{}
 			err = proto.Unmarshal(item.Payload, comments)
@@ -461,13 +461,12 @@ func (ac *AcFunLive) handleStateSignal(payload *[]byte, event bool) {
 				}
 				danmu[i] = d
 			}
-			if event {
-				ac.callEvent(recentCommentEvent, danmu)
-			} else {
+			if !event {
 				ac.info.Lock()
 				ac.info.RecentComment = danmu
 				ac.info.Unlock()
 			}
+			ac.emitEvent(event, EventRecentComment, danmu)
 		case "CommonStateSignalCurrentRedpackList":
 			redpackList := &acproto.CommonStateSignalCurrentRedpackList{}
 			err = proto.Unmarshal(item.Payload, redpackList)
@@ -486,129 +485,322 @@ func (ac *AcFunLive) handleStateSignal(payload *[]byte, event bool) {
 				}
 				redpacks[i] = r
 			}
-			if event {
-				ac.callEvent(redpackListEvent, redpacks)
-			} else {
+			if !event {
 				ac.info.Lock()
 				ac.info.RedpackList = redpacks
 				ac.info.Unlock()
 			}
+			ac.emitEvent(event, EventRedpackList, redpacks)
 		case "CommonStateSignalChatCall":
 			chatCall := &acproto.CommonStateSignalChatCall{}
 			err = proto.Unmarshal(item.Payload, chatCall)
 			checkErr(err)
-			if event {
-				ac.callEvent(chatCallEvent, &ChatCall{
-					ChatID:   chatCall.ChatId,
-					LiveID:   chatCall.LiveId,
-					CallTime: chatCall.CallTimestampMs,
-				})
-			}
+			ac.emitEvent(event, EventChatCall, &ChatCall{
+				ChatID:   chatCall.ChatId,
+				LiveID:   chatCall.LiveId,
+				CallTime: chatCall.CallTimestampMs,
+			})
 		case "CommonStateSignalChatAccept":
 			chatAccept := &acproto.CommonStateSignalChatAccept{}
 			err = proto.Unmarshal(item.Payload, chatAccept)
 			checkErr(err)
-			if event {
-				ac.callEvent(chatAcceptEvent, &ChatAccept{
-					ChatID:     chatAccept.ChatId,
-					MediaType:  ChatMediaType(chatAccept.MediaType),
-					SignalInfo: chatAccept.AryaSignalInfo,
-				})
-			}
+			ac.emitEvent(event, EventChatAccept, &ChatAccept{
+				ChatID:     chatAccept.ChatId,
+				MediaType:  ChatMediaType(chatAccept.MediaType),
+				SignalInfo: chatAccept.AryaSignalInfo,
+			})
 		case "CommonStateSignalChatReady":
 			chatReady := &acproto.CommonStateSignalChatReady{}
 			err = proto.Unmarshal(item.Payload, chatReady)
 			checkErr(err)
-			if event {
-				ac.callEvent(chatReadyEvent, &ChatReady{
-					ChatID:    chatReady.ChatId,
-					Guest:     *NewUserInfo(chatReady.GuestUserInfo),
-					MediaType: ChatMediaType(chatReady.MediaType),
-				})
-			}
+			ac.emitEvent(event, EventChatReady, &ChatReady{
+				ChatID:    chatReady.ChatId,
+				Guest:     *NewUserInfo(chatReady.GuestUserInfo),
+				MediaType: ChatMediaType(chatReady.MediaType),
+			})
 		case "CommonStateSignalChatEnd":
 			chatEnd := &acproto.CommonStateSignalChatEnd{}
 			err = proto.Unmarshal(item.Payload, chatEnd)
 			checkErr(err)
-			if event {
-				ac.callEvent(chatEndEvent, &ChatEnd{
-					ChatID:  chatEnd.ChatId,
-					EndType: ChatEndType(chatEnd.EndType),
-				})
-			}
+			ac.emitEvent(event, EventChatEnd, &ChatEnd{
+				ChatID:  chatEnd.ChatId,
+				EndType: ChatEndType(chatEnd.EndType),
+			})
 		//case "AuthorChatPlayerInfo":
 		case "CommonStateSignalAuthorChatCall":
 			chatCall := &acproto.CommonStateSignalAuthorChatCall{}
 			err = proto.Unmarshal(item.Payload, chatCall)
 			checkErr(err)
-			if event {
-				ac.callEvent(authorChatCallEvent, &AuthorChatCall{
-					Inviter: AuthorChatPlayerInfo{
-						UserInfo:               *NewUserInfo(chatCall.InviterUserInfo.Player),
-						LiveID:                 chatCall.InviterUserInfo.LiveId,
-						EnableJumpPeerLiveRoom: chatCall.InviterUserInfo.EnableJumpPeerLiveRoom,
-					},
-					ChatID:   chatCall.AuthorChatId,
-					CallTime: chatCall.CallTimestampMs,
-				})
-			}
+			ac.emitEvent(event, EventAuthorChatCall, &AuthorChatCall{
+				Inviter: AuthorChatPlayerInfo{
+					UserInfo:               *NewUserInfo(chatCall.InviterUserInfo.Player),
+					LiveID:                 chatCall.InviterUserInfo.LiveId,
+					EnableJumpPeerLiveRoom: chatCall.InviterUserInfo.EnableJumpPeerLiveRoom,
+				},
+				ChatID:   chatCall.AuthorChatId,
+				CallTime: chatCall.CallTimestampMs,
+			})
 		case "CommonStateSignalAuthorChatAccept":
 			chatAccept := &acproto.CommonStateSignalAuthorChatAccept{}
 			err = proto.Unmarshal(item.Payload, chatAccept)
 			checkErr(err)
-			if event {
-				ac.callEvent(authorChatAcceptEvent, &AuthorChatAccept{
-					ChatID:     chatAccept.AuthorChatId,
-					SignalInfo: chatAccept.AryaSignalInfo,
-				})
-			}
+			ac.emitEvent(event, EventAuthorChatAccept, &AuthorChatAccept{
+				ChatID:     chatAccept.AuthorChatId,
+				SignalInfo: chatAccept.AryaSignalInfo,
+			})
 		case "CommonStateSignalAuthorChatReady":
 			chatReady := &acproto.CommonStateSignalAuthorChatReady{}
 			err = proto.Unmarshal(item.Payload, chatReady)
 			checkErr(err)
-			if event {
-				ac.callEvent(authorChatReadyEvent, &AuthorChatReady{
-					Inviter: AuthorChatPlayerInfo{
-						UserInfo:               *NewUserInfo(chatReady.InviterUserInfo.Player),
-						LiveID:                 chatReady.InviterUserInfo.LiveId,
-						EnableJumpPeerLiveRoom: chatReady.InviterUserInfo.EnableJumpPeerLiveRoom,
-					},
-					Invitee: AuthorChatPlayerInfo{
-						UserInfo:               *NewUserInfo(chatReady.InviteeUserInfo.Player),
-						LiveID:                 chatReady.InviteeUserInfo.LiveId,
-						EnableJumpPeerLiveRoom: chatReady.InviteeUserInfo.EnableJumpPeerLiveRoom,
-					},
-					ChatID: chatReady.AuthorChatId,
-				})
-			}
+			ac.emitEvent(event, EventAuthorChatReady, &AuthorChatReady{
+				Inviter: AuthorChatPlayerInfo{
+					UserInfo:               *NewUserInfo(chatReady.InviterUserInfo.Player),
+					LiveID:                 chatReady.InviterUserInfo.LiveId,
+					EnableJumpPeerLiveRoom: chatReady.InviterUserInfo.EnableJumpPeerLiveRoom,
+				},
+				Invitee: AuthorChatPlayerInfo{
+					UserInfo:               *NewUserInfo(chatReady.InviteeUserInfo.Player),
+					LiveID:                 chatReady.InviteeUserInfo.LiveId,
+					EnableJumpPeerLiveRoom: chatReady.InviteeUserInfo.EnableJumpPeerLiveRoom,
+				},
+				ChatID: chatReady.AuthorChatId,
+			})
 		case "CommonStateSignalAuthorChatEnd":
 			chatEnd := &acproto.CommonStateSignalAuthorChatEnd{}
 			err = proto.Unmarshal(item.Payload, chatEnd)
 			checkErr(err)
-			if event {
-				ac.callEvent(authorChatEndEvent, &AuthorChatEnd{
-					ChatID:    chatEnd.AuthorChatId,
-					EndType:   ChatEndType(chatEnd.EndType),
-					EndLiveID: chatEnd.EndLiveId,
-				})
-			}
+			ac.emitEvent(event, EventAuthorChatEnd, &AuthorChatEnd{
+				ChatID:    chatEnd.AuthorChatId,
+				EndType:   ChatEndType(chatEnd.EndType),
+				EndLiveID: chatEnd.EndLiveId,
+			})
 		case "CommonStateSignalAuthorChatChangeSoundConfig":
 			soundConfig := &acproto.CommonStateSignalAuthorChatChangeSoundConfig{}
 			err = proto.Unmarshal(item.Payload, soundConfig)
 			checkErr(err)
-			if event {
-				ac.callEvent(authorChatChangeSoundConfigEvent, &AuthorChatChangeSoundConfig{
-					ChatID:                soundConfig.AuthorChatId,
-					SoundConfigChangeType: SoundConfigChangeType(soundConfig.SoundConfigChangeType),
+			ac.emitEvent(event, EventAuthorChatChangeSoundConfig, &AuthorChatChangeSoundConfig{
+				ChatID:                soundConfig.AuthorChatId,
+				SoundConfigChangeType: SoundConfigChangeType(soundConfig.SoundConfigChangeType),
+			})
+		case "CommonStateSignalPKInvitation":
+			invitation := &acproto.CommonStateSignalPKInvitation{}
+			err = proto.Unmarshal(item.Payload, invitation)
+			checkErr(err)
+			ac.emitEvent(event, EventPKInvitation, &PKInvitation{
+				PKID:       invitation.A,
+				Inviter:    newPKPlayerInfo(invitation.B),
+				InviteTime: invitation.C,
+			})
+		case "CommonStateSignalPKAccept":
+			accept := &acproto.CommonStateSignalPKAccept{}
+			err = proto.Unmarshal(item.Payload, accept)
+			checkErr(err)
+			ac.emitEvent(event, EventPKAccept, &PKAccept{
+				PKID:       accept.A,
+				SignalInfo: accept.B,
+			})
+		case "CommonStateSignalPKReady":
+			ready := &acproto.CommonStateSignalPKReady{}
+			err = proto.Unmarshal(item.Payload, ready)
+			checkErr(err)
+			players := make([]PKPlayerInfo, len(ready.B))
+			for i, player := range ready.B {
+				players[i] = newPKPlayerInfo(player)
+			}
+			if !event {
+				// 新的 PK 开始
+				ac.info.Lock()
+				ac.info.PKStatistic = PKStatistic{PKID: ready.A}
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventPKReady, &PKReady{
+				PKID:    ready.A,
+				Players: players,
+			})
+		case "CommonStateSignalPkStatistic":
+			statistic := &acproto.CommonStateSignalPkStatistic{}
+			err = proto.Unmarshal(item.Payload, statistic)
+			checkErr(err)
+			pk := newPKStatistic(statistic)
+			if !event {
+				ac.info.Lock()
+				ac.info.PKStatistic = pk
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventPKStatistic, &pk)
+		case "CommonStateSignalPkEnd":
+			end := &acproto.CommonStateSignalPkEnd{}
+			err = proto.Unmarshal(item.Payload, end)
+			checkErr(err)
+			ac.emitEvent(event, EventPKEnd, &PKEnd{
+				PKID:      end.A,
+				EndType:   PKEndType(end.B),
+				EndLiveID: end.C,
+			})
+		case "CommonStateSignalPKSoundConfigChanged":
+			soundConfig := &acproto.CommonStateSignalPKSoundConfigChanged{}
+			err = proto.Unmarshal(item.Payload, soundConfig)
+			checkErr(err)
+			ac.emitEvent(event, EventPKSoundConfigChanged, &PKSoundConfigChanged{
+				PKID:                  soundConfig.A,
+				SoundConfigChangeType: SoundConfigChangeType(soundConfig.B),
+			})
+		case "CommonStateSignalAuthorPause":
+			pause := &acproto.CommonStateSignalAuthorPause{}
+			err = proto.Unmarshal(item.Payload, pause)
+			checkErr(err)
+			if !event {
+				ac.info.Lock()
+				ac.info.AuthorPaused = true
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventAuthorPause, &AuthorPause{
+				PauseTime: pause.A,
+				Reason:    pause.B,
+			})
+		case "CommonStateSignalAuthorResume":
+			resume := &acproto.CommonStateSignalAuthorResume{}
+			err = proto.Unmarshal(item.Payload, resume)
+			checkErr(err)
+			if !event {
+				ac.info.Lock()
+				ac.info.AuthorPaused = false
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventAuthorResume, &AuthorResume{
+				ResumeTime: resume.A,
+			})
+		case "CommonStateSignalWidget":
+			widget := &acproto.CommonStateSignalWidget{}
+			err = proto.Unmarshal(item.Payload, widget)
+			checkErr(err)
+			widgets := make([]Widget, len(widget.A))
+			for i, w := range widget.A {
+				widgets[i] = newWidget(w)
+			}
+			if !event {
+				ac.info.Lock()
+				ac.info.Widgets = widgets
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventWidget, widgets)
+		case "CommonStateSignalWishSheetCurrentState":
+			wishSheet := &acproto.CommonStateSignalWishSheetCurrentState{}
+			err = proto.Unmarshal(item.Payload, wishSheet)
+			checkErr(err)
+			sheet := WishSheet{
+				WishSheetID: wishSheet.A,
+				Wishes:      make([]Wish, len(wishSheet.B)),
+			}
+			for i, wish := range wishSheet.B {
+				sheet.Wishes[i] = Wish{
+					WishID:       wish.A,
+					GiftID:       wish.B,
+					CurrentCount: wish.C,
+					TargetCount:  wish.D,
+					Description:  wish.E,
+					Extra:        wish.F,
+				}
+			}
+			if !event {
+				ac.info.Lock()
+				ac.info.WishSheet = sheet
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventWishSheet, &sheet)
+		case "TopBannerNotice":
+			notice := &acproto.TopBannerNotice{}
+			err = proto.Unmarshal(item.Payload, notice)
+			checkErr(err)
+			banner := newTopBanner(notice)
+			if !event {
+				ac.info.Lock()
+				ac.info.TopBanner = banner
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventTopBanner, &banner)
+		case "CommonStateSignalShoppingCart":
+			shoppingCart := &acproto.CommonStateSignalShoppingCart{}
+			err = proto.Unmarshal(item.Payload, shoppingCart)
+			checkErr(err)
+			cart := ShoppingCart{
+				State: int32(shoppingCart.A),
+				Data:  shoppingCart.B,
+			}
+			if !event {
+				ac.info.Lock()
+				ac.info.ShoppingCart = cart
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventShoppingCart, &cart)
+		case "KwaiStateSignalEcommerceCart":
+			ecommerceCart := &acproto.KwaiStateSignalEcommerceCart{}
+			err = proto.Unmarshal(item.Payload, ecommerceCart)
+			checkErr(err)
+			cart := EcommerceCart{
+				Show: ecommerceCart.A != 0,
+			}
+			if !event {
+				ac.info.Lock()
+				ac.info.EcommerceCart = cart
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventEcommerceCart, &cart)
+		case "KwaiStateSignalEcommerceCartItemPopup":
+			popup := &acproto.KwaiStateSignalEcommerceCartItemPopup{}
+			err = proto.Unmarshal(item.Payload, popup)
+			checkErr(err)
+			cartItem := EcommerceCartItemPopup{
+				Show:    popup.A != 0,
+				ItemID:  popup.B,
+				Title:   popup.C,
+				Price:   popup.D,
+				Picture: popup.E,
+			}
+			if !event {
+				ac.info.Lock()
+				ac.info.EcommerceItem = cartItem
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventEcommerceCartItemPopup, &cartItem)
+		case "CommonStateSignalNewApplyUser":
+			newApplyUser := &acproto.CommonStateSignalNewApplyUser{}
+			err = proto.Unmarshal(item.Payload, newApplyUser)
+			checkErr(err)
+			if !event {
+				ac.info.Lock()
+				if !slices.Contains(ac.info.ApplyUserIDs, newApplyUser.A) {
+					ac.info.ApplyUserIDs = append(ac.info.ApplyUserIDs, newApplyUser.A)
+				}
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventNewApplyUser, &ApplyUser{UserID: newApplyUser.A})
+		case "CommonStateSignalFeatureStateSync":
+			featureStateSync := &acproto.CommonStateSignalFeatureStateSync{}
+			err = proto.Unmarshal(item.Payload, featureStateSync)
+			checkErr(err)
+			features := make([]LiveFeature, 0, len(featureStateSync.FeatureState))
+			for _, f := range featureStateSync.FeatureState {
+				if f == nil {
+					continue
+				}
+				features = append(features, LiveFeature{
+					Type:  LiveFeatureType(f.Type),
+					State: LiveFeatureStateType(f.State),
 				})
 			}
+			if !event {
+				ac.info.Lock()
+				ac.info.Features = features
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventFeatureStateSync, features)
 		case "CommonStateSignalLiveState":
+			// 故意忽略：字段的意义不明，直播状态的变化已经由 ZtLiveScStatusChanged 触发 EventLiveStatusChanged，
+			// 这里也不交给 handleUnknownSignal()，以免每次都当作未知信号
 		case "CommonStateSignalArLiveTreasureBoxState":
 		default:
-			log.Printf("未知的State Signal item.SignalType：%s\npayload string:\n%s\npayload base64:\n%s\n",
-				item.SignalType,
-				string(item.Payload),
-				base64.StdEncoding.EncodeToString(item.Payload))
+			ac.handleUnknownSignal(SignalLayerState, item.SignalType, item.Payload)
 		}
 	}
 }
@@ -625,40 +817,200 @@ func (ac *AcFunLive) handleNotifySignal(payload *[]byte, event bool) {
 			kickedOut := &acproto.CommonNotifySignalKickedOut{}
 			err = proto.Unmarshal(item.Payload, kickedOut)
 			checkErr(err)
-			if event {
-				ac.callEvent(kickedOutEvent, kickedOut.Reason)
-			} else {
+			if !event {
 				ac.info.Lock()
 				ac.info.KickedOut = kickedOut.Reason
 				ac.info.Unlock()
 			}
+			ac.emitEvent(event, EventKickedOut, kickedOut.Reason)
+			ac.t.err.Store(fmt.Errorf("%w：%s", ErrKickedOut, kickedOut.Reason))
+			ac.clientStop("Kicked out")
 		case "CommonNotifySignalViolationAlert":
 			violationAlert := &acproto.CommonNotifySignalViolationAlert{}
 			err = proto.Unmarshal(item.Payload, violationAlert)
 			checkErr(err)
-			if event {
-				ac.callEvent(violationAlertEvent, violationAlert.ViolationContent)
-			} else {
+			if !event {
 				ac.info.Lock()
 				ac.info.ViolationAlert = violationAlert.ViolationContent
 				ac.info.Unlock()
 			}
+			ac.emitEvent(event, EventViolationAlert, violationAlert.ViolationContent)
 		case "CommonNotifySignalLiveManagerState":
 			liveManagerState := &acproto.CommonNotifySignalLiveManagerState{}
 			err = proto.Unmarshal(item.Payload, liveManagerState)
 			checkErr(err)
-			if event {
-				ac.callEvent(managerStateEvent, ManagerState(liveManagerState.State))
-			} else {
+			if !event {
 				ac.info.Lock()
 				ac.info.LiveManagerState = ManagerState(liveManagerState.State)
 				ac.info.Unlock()
 			}
+			ac.emitEvent(event, EventManagerState, ManagerState(liveManagerState.State))
+		case "CommonNotifySignalRemoveApplyUser":
+			removeApplyUser := &acproto.CommonNotifySignalRemoveApplyUser{}
+			err = proto.Unmarshal(item.Payload, removeApplyUser)
+			checkErr(err)
+			if !event {
+				ac.info.Lock()
+				ac.info.ApplyUserIDs = slices.DeleteFunc(ac.info.ApplyUserIDs, func(uid int64) bool {
+					return uid == removeApplyUser.A
+				})
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventRemoveApplyUser, &RemoveApplyUser{
+				UserID: removeApplyUser.A,
+				Reason: int32(removeApplyUser.B),
+			})
+		case "CommonNotifySignalCoverAuditResult":
+			coverAuditResult := &acproto.CommonNotifySignalCoverAuditResult{}
+			err = proto.Unmarshal(item.Payload, coverAuditResult)
+			checkErr(err)
+			if !event {
+				ac.info.Lock()
+				ac.info.CoverAuditResult = CoverAuditResultType(coverAuditResult.AuditStatus)
+				ac.info.Unlock()
+			}
+			ac.emitEvent(event, EventCoverAuditResult, CoverAuditResultType(coverAuditResult.AuditStatus))
 		default:
-			log.Printf("未知的Notify Signal signalType：%s\npayload string:\n%s\npayload base64:\n%s\n",
-				item.SignalType,
-				string(item.Payload),
-				base64.StdEncoding.EncodeToString(item.Payload))
+			ac.handleUnknownSignal(SignalLayerNotify, item.SignalType, item.Payload)
+		}
+	}
+}
+
+// 生成 PKPlayerInfo
+func newPKPlayerInfo(player *acproto.PkPlayerInfo) PKPlayerInfo {
+	if player == nil {
+		return PKPlayerInfo{}
+	}
+	info := PKPlayerInfo{
+		LiveID:                 player.B,
+		EnableJumpPeerLiveRoom: player.C,
+	}
+	if player.A != nil {
+		info.UserInfo = *NewUserInfo(player.A)
+	}
+
+	return info
+}
+
+// 生成 PKStatistic
+func newPKStatistic(statistic *acproto.CommonStateSignalPkStatistic) PKStatistic {
+	pk := PKStatistic{
+		PKID:      statistic.A,
+		BeginTime: statistic.B,
+		Duration:  statistic.C,
+	}
+
+	pk.Players = make([]PKPlayerStatistic, len(statistic.J))
+	for i, player := range statistic.J {
+		p := PKPlayerStatistic{
+			LiveID: player.B,
+			Score:  player.C,
+		}
+		if player.A != nil {
+			p.UserInfo = *NewUserInfo(player.A)
+		}
+		p.RoundScores = make([]PKPlayerRoundScore, len(player.D))
+		for j, round := range player.D {
+			p.RoundScores[j] = PKPlayerRoundScore{
+				Score: round.A,
+				Round: round.B,
+			}
+		}
+		pk.Players[i] = p
+	}
+
+	pk.Contributions = make([]PKContribution, len(statistic.I))
+	for i, contribution := range statistic.I {
+		c := PKContribution{UserID: contribution.A}
+		c.Contributors = make([]PKContributor, len(contribution.B))
+		for j, detail := range contribution.B {
+			if detail.A != nil {
+				c.Contributors[j].UserInfo = *NewUserInfo(detail.A)
+			}
+			c.Contributors[j].Contribution = detail.B
+		}
+		pk.Contributions[i] = c
+	}
+
+	if statistic.K != nil {
+		pk.Round = PKRoundInfo{
+			BeginTime:    statistic.K.A,
+			CurrentRound: statistic.K.B,
+			TotalRound:   statistic.K.C,
+			EndTime:      statistic.K.D,
+			State:        int32(statistic.K.E),
+		}
+	}
+
+	return pk
+}
+
+// 返回第一个图片链接
+func firstImageURL(images []*acproto.ImageCdnNode) string {
+	for _, image := range images {
+		if image != nil && image.Url != "" {
+			return image.Url
+		}
+	}
+
+	return ""
+}
+
+// 生成 Widget
+func newWidget(item *acproto.WidgetItem) Widget {
+	widget := Widget{
+		WidgetID:  item.A,
+		Name:      item.B,
+		Type:      item.C,
+		StartTime: item.D,
+		EndTime:   item.E,
+		URL:       item.G,
+	}
+	if item.F != nil {
+		if item.F.A != nil {
+			widget.Picture = item.F.A.Url
 		}
+		widget.PictureWidth = item.F.B
+		widget.PictureHeight = item.F.C
+	}
+
+	return widget
+}
+
+// 生成 TopBanner
+func newTopBanner(notice *acproto.TopBannerNotice) TopBanner {
+	banner := TopBanner{
+		BannerID:        notice.A,
+		Title:           notice.B,
+		Content:         notice.C,
+		Type:            notice.E,
+		Icon:            firstImageURL(notice.G),
+		DisplayDuration: notice.H,
+	}
+	if notice.D != nil {
+		banner.BackgroundColor = notice.D.A
+		banner.BackgroundImage = firstImageURL(notice.D.B)
+	}
+	if notice.F != nil {
+		banner.ButtonText = notice.F.A
+		banner.ButtonURL = notice.F.B
 	}
+
+	return banner
+}
+
+// 处理未知的信号，无论是否采用事件响应模式都会调用 OnUnknownSignal 的 handler
+func (ac *AcFunLive) handleUnknownSignal(layer SignalLayer, signalType string, payload []byte) {
+	if !ac.quietUnknown {
+		ac.t.logger().Warn("未知的弹幕数据",
+			"layer", layer,
+			"signalType", signalType,
+			"payload", string(payload),
+			"base64", base64.StdEncoding.EncodeToString(payload))
+	}
+	ac.callEvent(EventUnknownSignal, UnknownSignal{
+		Layer:   layer,
+		Type:    signalType,
+		Payload: append([]byte{}, payload...),
+	})
 }
@@ -2,6 +2,7 @@ package acfundanmu
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha256"
@@ -28,6 +29,7 @@ var defaultClient = &fasthttp.Client{
 	MaxIdleConnDuration: maxIdleConnDuration,
 	ReadTimeout:         timeout,
 	WriteTimeout:        timeout,
+	Dial:                defaultDial,
 }
 
 // 生成随机数字的字符串
@@ -43,7 +45,9 @@ func genRandomNum() string {
 
 // HTTP 客户端
 type httpClient struct {
+	ctx         context.Context // 为 nil 时不能取消请求
 	client      *fasthttp.Client
+	endpoints   *endpointMap // 为 nil 时使用全局的 host 替换
 	url         string
 	body        []byte
 	method      string
@@ -53,13 +57,23 @@ type httpClient struct {
 	userAgent   string
 	deviceID    string
 	noReqID     bool
+	readOnly    bool // 请求不会改变服务器的状态，不是 GET 请求时也可以安全地重试
+}
+
+// 标记只读请求的 context key
+type readOnlyKey struct{}
+
+// 判断 ctx 是否属于只读的请求
+func readOnlyRequest(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readOnly
 }
 
 // 完成 http 请求，调用后需要 defer fasthttp.ReleaseResponse(resp)
 func (c *httpClient) doRequest() (resp *fasthttp.Response, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("doRequest() error: %v", err)
+			e = fmt.Errorf("doRequest() error: %w", recoverErr(err))
 			fasthttp.ReleaseResponse(resp)
 		}
 	}()
@@ -74,6 +88,7 @@ func (c *httpClient) doRequest() (resp *fasthttp.Response, e error) {
 
 	if c.url != "" {
 		req.SetRequestURI(c.url)
+		c.endpoints.rewrite(req)
 	} else {
 		panic(fmt.Errorf("请求的 url 不能为空"))
 	}
@@ -127,17 +142,64 @@ func (c *httpClient) doRequest() (resp *fasthttp.Response, e error) {
 
 	req.Header.Set("Accept-Encoding", "gzip")
 
-	err := c.client.Do(req, resp)
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.readOnly {
+		ctx = context.WithValue(ctx, readOnlyKey{}, true)
+	}
+	err := wrapHTTPMiddleware(c.do)(ctx, req, resp)
 	checkErr(err)
 
 	return resp, nil
 }
 
+// 完成 http 请求，ctx 结束时不再等待响应并返回 ctx.Err()
+func (c *httpClient) do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	if ctx.Done() == nil {
+		return c.client.Do(req, resp)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// ctx 结束后请求可能还在进行，需要使用单独的 req 和 resp
+	r := fasthttp.AcquireRequest()
+	req.CopyTo(r)
+	res := fasthttp.AcquireResponse()
+	done := make(chan error, 1)
+	go func() {
+		if deadline, ok := ctx.Deadline(); ok {
+			done <- c.client.DoDeadline(r, res, deadline)
+		} else {
+			done <- c.client.Do(r, res)
+		}
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			res.CopyTo(resp)
+		}
+		fasthttp.ReleaseRequest(r)
+		fasthttp.ReleaseResponse(res)
+		return err
+	case <-ctx.Done():
+		go func() {
+			<-done
+			fasthttp.ReleaseRequest(r)
+			fasthttp.ReleaseResponse(res)
+		}()
+		return ctx.Err()
+	}
+}
+
 // http 请求，返回响应 body
 func (c *httpClient) request() (body []byte, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("request() error: %v", err)
+			e = fmt.Errorf("request() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -145,14 +207,14 @@ func (c *httpClient) request() (body []byte, e error) {
 	checkErr(err)
 	defer fasthttp.ReleaseResponse(resp)
 
-	return getBody(resp), nil
+	return takeBody(resp), nil
 }
 
 // http 请求，返回响应 body 和 cookies
 func (c *httpClient) getCookies() (body []byte, cookies Cookies, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getCookies() error: %v", err)
+			e = fmt.Errorf("getCookies() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -167,14 +229,14 @@ func (c *httpClient) getCookies() (body []byte, cookies Cookies, e error) {
 		cookies = append(cookies, cookie)
 	})
 
-	return getBody(resp), cookies, nil
+	return takeBody(resp), cookies, nil
 }
 
-// 通过快手 API 获取数据，form 为 nil 时采用默认 form，sign 为 true 时会对请求签名
-func (t *token) fetchKuaiShouAPI(url string, form *fasthttp.Args, sign bool) (body []byte, e error) {
+// 通过快手 API 获取数据，form 为 nil 时采用默认 form，sign 为 true 时会对请求签名，readOnly 为 true 时请求不会改变服务器的状态，可以安全地重试
+func (t *token) fetchKuaiShouAPI(ctx context.Context, url string, form *fasthttp.Args, sign, readOnly bool) (body []byte, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("fetchKuaiShouAPI() error: %v", err)
+			e = fmt.Errorf("fetchKuaiShouAPI() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -195,12 +257,16 @@ func (t *token) fetchKuaiShouAPI(url string, form *fasthttp.Args, sign bool) (bo
 		form.Set("__clientSign", clientSign)
 	}
 	client := &httpClient{
+		client:      t.client,
+		endpoints:   t.endpoints,
+		ctx:         ctx,
 		url:         apiURL,
 		body:        form.QueryString(),
 		method:      "POST",
 		contentType: formContentType,
 		referer:     t.livePage,
 		noReqID:     true,
+		readOnly:    readOnly,
 	}
 
 	return client.request()
@@ -214,25 +280,28 @@ func (t *token) defaultForm(liveID string) *fasthttp.Args {
 	return form
 }
 
-// 获取响应 body
-func getBody(resp *fasthttp.Response) []byte {
-	if string(resp.Header.Peek("content-encoding")) == "gzip" || string(resp.Header.Peek("Content-Encoding")) == "gzip" {
+// 判断响应 body 是否用 gzip 压缩
+func isGzip(resp *fasthttp.Response) bool {
+	return bytes.Equal(resp.Header.ContentEncoding(), []byte("gzip"))
+}
+
+// 取出响应 body，没有压缩时从 resp 取走 body 的缓冲区而不复制，resp 被放回 pool 后仍然可以使用
+func takeBody(resp *fasthttp.Response) []byte {
+	if isGzip(resp) {
 		body, err := resp.BodyGunzip()
 		if err == nil {
 			return body
 		}
 	}
 
-	body := append([]byte{}, resp.Body()...)
-
-	return body
+	return resp.SwapBody(nil)
 }
 
 // 生成 client sign
 func (t *token) genClientSign(url string, form *fasthttp.Args) (clientSign string, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("genClientSign() error: %v", err)
+			e = fmt.Errorf("genClientSign() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -281,5 +350,10 @@ func (t *token) genClientSign(url string, form *fasthttp.Args) (clientSign strin
 
 // FetchKuaiShouAPI 获取快手 API 的响应，测试用
 func (ac *AcFunLive) FetchKuaiShouAPI(url string, form *fasthttp.Args, sign bool) (body []byte, e error) {
-	return ac.t.fetchKuaiShouAPI(url, form, sign)
+	return ac.FetchKuaiShouAPICtx(context.Background(), url, form, sign)
+}
+
+// FetchKuaiShouAPICtx 和 FetchKuaiShouAPI() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) FetchKuaiShouAPICtx(ctx context.Context, url string, form *fasthttp.Args, sign bool) (body []byte, e error) {
+	return ac.t.fetchKuaiShouAPI(ctx, url, form, sign, false)
 }
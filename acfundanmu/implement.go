@@ -10,6 +10,7 @@ var _ DanmuMessage = (*Gift)(nil)
 var _ DanmuMessage = (*RichText)(nil)
 var _ DanmuMessage = (*JoinClub)(nil)
 var _ DanmuMessage = (*ShareLive)(nil)
+var _ DanmuMessage = (*EventMessage)(nil)
 
 // GetSendTime 获取弹幕发送时间
 func (d *Comment) GetSendTime() int64 {
@@ -115,6 +116,16 @@ func (d *ShareLive) GetUserInfo() *UserInfo {
 	return &info
 }
 
+// GetSendTime 获取事件的时间，实际上返回的是收到事件的时间
+func (d *EventMessage) GetSendTime() int64 {
+	return d.ReceiveTime
+}
+
+// GetUserInfo 获取事件的用户信息，总是返回 nil
+func (d *EventMessage) GetUserInfo() *UserInfo {
+	return nil
+}
+
 // 验证是否实现了 RichTextSegment 接口
 var _ RichTextSegment = (*RichTextUserInfo)(nil)
 var _ RichTextSegment = (*RichTextPlain)(nil)
@@ -1,8 +1,8 @@
 package acfundanmu
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"strconv"
 	"time"
 
@@ -16,7 +16,7 @@ func login(account, password string) (cookies Cookies, e error) {
 	defer func() {
 		if err := recover(); err != nil {
 			cookies = nil
-			e = fmt.Errorf("login() error: %v", err)
+			e = fmt.Errorf("login() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -45,7 +45,7 @@ func login(account, password string) (cookies Cookies, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("以注册用户的身份登陆AcFun失败，响应为 %s", string(body)))
+		panic(newAPIError("以注册用户的身份登陆AcFun失败", v, body))
 	}
 
 	return cookies, nil
@@ -55,7 +55,7 @@ func loginWithQRCode(qrCodeCallback func(QRCode), scannedCallback func()) (cooki
 	defer func() {
 		if err := recover(); err != nil {
 			cookies = nil
-			e = fmt.Errorf("loginWithQRCode() error: %v", err)
+			e = fmt.Errorf("loginWithQRCode() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -69,30 +69,30 @@ func loginWithQRCode(qrCodeCallback func(QRCode), scannedCallback func()) (cooki
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取登陆二维码失败，响应为 %s", string(body)))
+		panic(newAPIError("获取登陆二维码失败", v, body))
 	}
 
 	expireTime := v.GetInt64("expireTime")
 	if expireTime <= 0 {
-		panic(fmt.Errorf("获取登陆二维码失效时间失败，响应为 %s", string(body)))
+		panic(newAPIError("获取登陆二维码失效时间失败", v, body))
 	}
 	qrLoginSignature := string(v.GetStringBytes("qrLoginSignature"))
 	if len(qrLoginSignature) == 0 {
-		panic(fmt.Errorf("获取qrLoginSignature失败，响应为 %s", string(body)))
+		panic(newAPIError("获取qrLoginSignature失败", v, body))
 	}
 	imageData := string(v.GetStringBytes("imageData"))
 	if len(imageData) == 0 {
-		panic(fmt.Errorf("获取imageData失败，响应为 %s", string(body)))
+		panic(newAPIError("获取imageData失败", v, body))
 	}
 	qrLoginToken := string(v.GetStringBytes("qrLoginToken"))
 	if len(qrLoginToken) == 0 {
-		panic(fmt.Errorf("获取qrLoginToken失败，响应为 %s", string(body)))
+		panic(newAPIError("获取qrLoginToken失败", v, body))
 	}
 	qrCodeCallback(QRCode{ExpireTime: t + expireTime, ImageData: imageData})
 
 	// 留 10 秒多余的时间
 	expired := time.Duration((expireTime/1000 + 10) * int64(time.Second))
-	fasthttpClient := &fasthttp.Client{MaxIdleConnDuration: expired, ReadTimeout: expired, WriteTimeout: expired}
+	fasthttpClient := &fasthttp.Client{MaxIdleConnDuration: expired, ReadTimeout: expired, WriteTimeout: expired, Dial: defaultDial}
 	t = time.Now().UnixMilli()
 	client = &httpClient{
 		client:  fasthttpClient,
@@ -109,15 +109,15 @@ func loginWithQRCode(qrCodeCallback func(QRCode), scannedCallback func()) (cooki
 			return nil, nil
 		}
 
-		panic(fmt.Errorf("获取登陆二维码扫描状态失败，响应为 %s", string(body)))
+		panic(newAPIError("获取登陆二维码扫描状态失败", v, body))
 	}
 	if string(v.GetStringBytes("status")) != "SCANNED" {
-		panic(fmt.Errorf("获取登陆二维码扫描状态失败，响应为 %s", string(body)))
+		panic(newAPIError("获取登陆二维码扫描状态失败", v, body))
 	}
 
 	qrLoginSignature = string(v.GetStringBytes("qrLoginSignature"))
 	if len(qrLoginSignature) == 0 {
-		panic(fmt.Errorf("获取qrLoginSignature失败，响应为 %s", string(body)))
+		panic(newAPIError("获取qrLoginSignature失败", v, body))
 	}
 	scannedCallback()
 
@@ -142,10 +142,10 @@ func loginWithQRCode(qrCodeCallback func(QRCode), scannedCallback func()) (cooki
 			return nil, nil
 		}
 
-		panic(fmt.Errorf("扫描二维码登陆失败，响应为 %s", string(body)))
+		panic(newAPIError("扫描二维码登陆失败", v, body))
 	}
 	if string(v.GetStringBytes("status")) != "ACCEPTED" {
-		panic(fmt.Errorf("扫描二维码登陆失败，响应为 %s", string(body)))
+		panic(newAPIError("扫描二维码登陆失败", v, body))
 	}
 
 	return cookies, nil
@@ -155,11 +155,11 @@ func loginWithQRCode(qrCodeCallback func(QRCode), scannedCallback func()) (cooki
 func (t *token) getAcFunToken() (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getAcFunToken() error: %v", err)
+			e = fmt.Errorf("getAcFunToken() error: %w", recoverErr(err))
 		}
 	}()
 
-	deviceID, err := getDeviceID()
+	deviceID, err := t.getDeviceID(context.Background())
 	checkErr(err)
 	t.DeviceID = deviceID
 
@@ -169,10 +169,12 @@ func (t *token) getAcFunToken() (e error) {
 	if len(t.Cookies) != 0 {
 		form.Set(sid, midground)
 		client = &httpClient{
-			url:     getTokenURL,
-			body:    form.QueryString(),
-			cookies: t.Cookies,
-			referer: t.livePage,
+			client:    t.client,
+			endpoints: t.endpoints,
+			url:       getTokenURL,
+			body:      form.QueryString(),
+			cookies:   t.Cookies,
+			referer:   t.livePage,
 		}
 	} else {
 		form.Set(sid, visitor)
@@ -181,10 +183,12 @@ func (t *token) getAcFunToken() (e error) {
 		cookie.SetKey("_did")
 		cookie.SetValue(t.DeviceID)
 		client = &httpClient{
-			url:     loginURL,
-			body:    form.QueryString(),
-			cookies: []*fasthttp.Cookie{cookie},
-			referer: t.livePage,
+			client:    t.client,
+			endpoints: t.endpoints,
+			url:       loginURL,
+			body:      form.QueryString(),
+			cookies:   []*fasthttp.Cookie{cookie},
+			referer:   t.livePage,
 		}
 	}
 	client.method = "POST"
@@ -197,7 +201,7 @@ func (t *token) getAcFunToken() (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取AcFun token失败，响应为 %s", string(body)))
+		panic(newAPIError("获取AcFun token失败", v, body))
 	}
 
 	// 获取 userId 和对应的令牌
@@ -222,7 +226,7 @@ func (t *token) getAcFunToken() (e error) {
 func (t *token) getLiveToken() (stream StreamInfo, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getLiveToken() error: %v", err)
+			e = fmt.Errorf("getLiveToken() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -244,9 +248,12 @@ func (t *token) getLiveToken() (stream StreamInfo, e error) {
 	form.Set("authorId", strconv.FormatInt(t.liverUID, 10))
 	form.Set("pullStreamType", "FLV")
 	client := &httpClient{
+		client:      t.client,
+		endpoints:   t.endpoints,
 		url:         play,
 		body:        form.QueryString(),
 		method:      "POST",
+		readOnly:    true,
 		contentType: formContentType,
 		referer:     t.livePage, // 会验证 Referer
 		noReqID:     true,
@@ -259,11 +266,18 @@ func (t *token) getLiveToken() (stream StreamInfo, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取直播详细信息失败，响应为 %s", string(body)))
+		apiErr := newAPIError("获取直播详细信息失败", v, body)
+		if t.notLive() {
+			panic(fmt.Errorf("%w：%w", ErrNotLive, apiErr))
+		}
+		panic(apiErr)
 	}
 
 	v = v.Get("data")
 	liveID := string(v.GetStringBytes("liveId"))
+	if liveID == "" {
+		panic(ErrNotLive)
+	}
 	enterRoomAttach := string(v.GetStringBytes("enterRoomAttach"))
 	availableTickets := v.GetArray("availableTickets")
 	tickets := make([]string, len(availableTickets))
@@ -282,8 +296,9 @@ func (t *token) getLiveToken() (stream StreamInfo, e error) {
 	t.heartbeatSeqID = 0
 	t.ticketIndex = atomic.NewUint32(0)
 	t.err = atomic.NewError(nil)
+	t.closed = atomic.NewBool(false)
 
-	giftList, err := t.getGiftList(t.liveID)
+	giftList, err := t.getGiftList(context.Background(), t.liveID)
 	checkErr(err)
 	t.gifts = giftList
 
@@ -311,11 +326,22 @@ func (t *token) getLiveToken() (stream StreamInfo, e error) {
 	return stream, nil
 }
 
+// 判断主播是否没有在直播，主播的直播信息里没有 liveID 时为 true，获取直播信息失败时为 false
+func (t *token) notLive() bool {
+	info, err := t.getUserLiveInfo(context.Background(), t.liverUID)
+	if err != nil {
+		t.logger().Warn("获取主播的直播信息失败", "error", err)
+		return false
+	}
+
+	return info.LiveID == ""
+}
+
 // 获取全部 token
 func (t *token) getToken() (stream StreamInfo, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getToken() error: %v", err)
+			e = fmt.Errorf("getToken() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -328,16 +354,19 @@ func (t *token) getToken() (stream StreamInfo, e error) {
 }
 
 // 获取设备 ID
-func getDeviceID() (devideID string, e error) {
+func (t *token) getDeviceID(ctx context.Context) (devideID string, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getDeviceID() error: %v", err)
+			e = fmt.Errorf("getDeviceID() error: %w", recoverErr(err))
 		}
 	}()
 
 	client := &httpClient{
-		url:    liveHost,
-		method: "GET",
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       liveHost,
+		method:    "GET",
 	}
 	resp, err := client.doRequest()
 	checkErr(err)
@@ -347,23 +376,23 @@ func getDeviceID() (devideID string, e error) {
 	defer fasthttp.ReleaseCookie(didCookie)
 	didCookie.SetKey("_did")
 	if !resp.Header.Cookie(didCookie) {
-		panic("无法获取 didCookie")
+		panic(fmt.Errorf("无法获取 didCookie"))
 	}
 
 	return string(didCookie.Value()), nil
 }
 
 // 获取礼物列表
-func (t *token) getGiftList(liveID string) (giftList map[int64]GiftDetail, e error) {
+func (t *token) getGiftList(ctx context.Context, liveID string) (giftList map[int64]GiftDetail, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getGiftList() error: %v", err)
+			e = fmt.Errorf("getGiftList() error: %w", recoverErr(err))
 		}
 	}()
 
 	form := t.defaultForm(liveID)
 	defer fasthttp.ReleaseArgs(form)
-	body, err := t.fetchKuaiShouAPI(giftURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, giftURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -371,7 +400,7 @@ func (t *token) getGiftList(liveID string) (giftList map[int64]GiftDetail, e err
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取直播间的礼物列表失败，响应为 %s", string(body)))
+		panic(newAPIError("获取直播间的礼物列表失败", v, body))
 	}
 
 	return updateGiftList(v), nil
@@ -423,7 +452,7 @@ func updateGiftList(v *fastjson.Value) map[int64]GiftDetail {
 			case "cornerMarkerText":
 				g.CornerMarkerText = string(v.GetStringBytes())
 			default:
-				log.Printf("礼物列表里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+				defaultLogger().Warn("礼物列表里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 			}
 		})
 		gifts[g.GiftID] = g
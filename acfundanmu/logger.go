@@ -0,0 +1,58 @@
+package acfundanmu
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogHandler 就是日志的 handler，和 slog.Handler 兼容
+type LogHandler = slog.Handler
+
+// 不输出任何日志的 handler
+type discardHandler struct{}
+
+// Enabled 总是返回 false
+func (discardHandler) Enabled(context.Context, slog.Level) bool { return false }
+
+// Handle 丢弃日志
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+
+// WithAttrs 返回自身
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+// WithGroup 返回自身
+func (h discardHandler) WithGroup(string) slog.Handler { return h }
+
+// DiscardLogHandler 不输出任何日志的 handler，可以用于 SetLogger()
+var DiscardLogHandler LogHandler = discardHandler{}
+
+// 返回没有 AcFunLive 时使用的日志，也就是 slog.Default()
+func defaultLogger() *slog.Logger {
+	return slog.Default()
+}
+
+// 缓存的带有主播 uid 和 liveID 的日志
+type tokenLogger struct {
+	base     *slog.Logger
+	liverUID int64
+	liveID   string
+	l        *slog.Logger
+}
+
+// 返回带有主播 uid 和 liveID 的日志，没有设置日志时使用 slog.Default()。
+// 日志会被缓存，只在日志、主播 uid 或 liveID 改变时重新生成。
+func (t *token) logger() *slog.Logger {
+	base := t.log
+	if base == nil {
+		base = defaultLogger()
+	}
+	liverUID, liveID := t.liverUID, t.liveID
+	if c := t.logCache.Load(); c != nil && c.base == base && c.liverUID == liverUID && c.liveID == liveID {
+		return c.l
+	}
+
+	l := base.With(slog.Int64("liverUID", liverUID), slog.String("liveID", liveID))
+	t.logCache.Store(&tokenLogger{base: base, liverUID: liverUID, liveID: liveID, l: l})
+
+	return l
+}
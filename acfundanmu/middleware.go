@@ -0,0 +1,257 @@
+package acfundanmu
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fastjson"
+)
+
+// HTTPDoFunc 完成一次 http 请求，ctx 不会为 nil
+type HTTPDoFunc func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error
+
+// HTTPMiddleware 包装 HTTPDoFunc 的中间件，可以在请求前后做额外的处理
+type HTTPMiddleware func(next HTTPDoFunc) HTTPDoFunc
+
+// 全局的 http 中间件
+var httpMiddlewares struct {
+	sync.RWMutex
+	middlewares []HTTPMiddleware
+}
+
+// SetHTTPMiddleware 设置所有 http 请求（包括 GetUserLiveInfo() 等不需要 AcFunLive 的函数）使用的中间件，
+// 第一个中间件在最外层，不调用或参数为空时不使用任何中间件。
+// 应该在发起请求前调用，会替换之前设置的中间件。
+func SetHTTPMiddleware(middlewares ...HTTPMiddleware) {
+	httpMiddlewares.Lock()
+	defer httpMiddlewares.Unlock()
+	httpMiddlewares.middlewares = slices.Clone(middlewares)
+}
+
+// 用全局的中间件包装 do
+func wrapHTTPMiddleware(do HTTPDoFunc) HTTPDoFunc {
+	httpMiddlewares.RLock()
+	defer httpMiddlewares.RUnlock()
+	for i := len(httpMiddlewares.middlewares) - 1; i >= 0; i-- {
+		do = httpMiddlewares.middlewares[i](do)
+	}
+
+	return do
+}
+
+// RetryConfig 是重试中间件的设置
+type RetryConfig struct {
+	MaxRetries       int           // 最多重试的次数
+	BaseDelay        time.Duration // 第一次重试前等待的时间，之后每次翻倍，为 0 时默认为 500 毫秒
+	MaxDelay         time.Duration // 重试前最多等待的时间，为 0 时默认为 10 秒
+	TooFrequentDelay time.Duration // 服务器返回请求过于频繁时至少等待的时间，为 0 时默认为 5 秒
+	TooFrequentCodes []int         // 表示请求过于频繁的响应 result，响应的 HTTP 状态码为 429 或响应的错误信息（error_msg 等字段）包含“频繁”时也会被认为是请求过于频繁
+	// 为 true 时网络错误或 HTTP 状态码为 5xx 时也会重试 POST 等不是幂等的请求（如 ManagerKick()、StartLive()），
+	// 默认只重试 GET、HEAD 等幂等的请求和库里标记为只读的请求，以免重复执行操作
+	RetryNonIdempotent bool
+	// 判断网络错误或 HTTP 状态码为 5xx 时请求是否可以重试，返回 true 时重试，可以用来允许重试指定的 API，
+	// 返回 false 或为 nil 时按 RetryNonIdempotent 的说明处理
+	Retryable func(req *fasthttp.Request) bool
+}
+
+// NewRetryMiddleware 返回重试中间件，网络错误、HTTP 状态码为 5xx 或服务器返回请求过于频繁时会等待一段时间（指数退避加上随机抖动）后重试。
+// 服务器返回请求过于频繁时请求没有被执行，所以所有请求都会重试。
+// 网络错误和 5xx 默认只重试幂等的请求，AcFun 的 API 大多是 POST 请求，库里获取数据的 API（如 GetWatchingList()、GetGiftList()）被标记为只读，可以重试，
+// 执行操作的 API（如 ManagerKick()、StartLive()）和 FetchKuaiShouAPI() 不会重试，见 RetryConfig 的 RetryNonIdempotent 和 Retryable
+func NewRetryMiddleware(config RetryConfig) HTTPMiddleware {
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = 500 * time.Millisecond
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 10 * time.Second
+	}
+	if config.TooFrequentDelay <= 0 {
+		config.TooFrequentDelay = 5 * time.Second
+	}
+	codes := slices.Clone(config.TooFrequentCodes)
+
+	return func(next HTTPDoFunc) HTTPDoFunc {
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			for i := 0; ; i++ {
+				err := next(ctx, req, resp)
+				if i >= config.MaxRetries || ctx.Err() != nil {
+					return err
+				}
+
+				var minDelay time.Duration
+				retryable := config.RetryNonIdempotent || idempotent(req) || readOnlyRequest(ctx) ||
+					(config.Retryable != nil && config.Retryable(req))
+				switch {
+				case err != nil || resp.StatusCode() >= fasthttp.StatusInternalServerError:
+					if !retryable {
+						return err
+					}
+				case tooFrequent(resp, codes):
+					minDelay = config.TooFrequentDelay
+				default:
+					return nil
+				}
+
+				delay := backoff(config.BaseDelay, config.MaxDelay, i)
+				if delay < minDelay {
+					delay = minDelay
+				}
+				defaultLogger().Debug("http 请求失败，等待后重试",
+					"url", string(req.URI().FullURI()),
+					"status", resp.StatusCode(),
+					"error", err,
+					"retry", i+1,
+					"delay", delay,
+				)
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+				resp.Reset()
+			}
+		}
+	}
+}
+
+// 返回第 n 次重试前等待的时间，在 [d/2, d) 里随机选取，d 为 base*2^n 和 max 的较小值
+func backoff(base, max time.Duration, n int) time.Duration {
+	d := max
+	if n < 30 && base<<n < max {
+		d = base << n
+	}
+	half := d / 2
+
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// 判断请求是否幂等，幂等的请求重复执行不会有副作用
+func idempotent(req *fasthttp.Request) bool {
+	h := &req.Header
+	return h.IsGet() || h.IsHead() || h.IsOptions() || h.IsTrace() || h.IsPut() || h.IsDelete()
+}
+
+// 判断服务器是否返回请求过于频繁，只检查 HTTP 状态码和响应的 result、错误信息，不检查其他内容。
+// 先用字节匹配筛选，可能是请求过于频繁时才解析 json
+func tooFrequent(resp *fasthttp.Response, codes []int) bool {
+	if resp.StatusCode() == fasthttp.StatusTooManyRequests {
+		return true
+	}
+
+	body := resp.Body()
+	if isGzip(resp) {
+		d, data, err := gunzip(body)
+		if err != nil {
+			return false
+		}
+		defer releaseGzip(d)
+		body = data
+	}
+	if !bytes.Contains(body, []byte("频繁")) && !containsResult(body, codes) {
+		return false
+	}
+
+	p := generalParserPool.Get()
+	defer generalParserPool.Put(p)
+	v, err := p.ParseBytes(body)
+	if err != nil || v.Type() != fastjson.TypeObject {
+		return false
+	}
+	if len(codes) != 0 && v.Exists("result") && slices.Contains(codes, v.GetInt("result")) {
+		return true
+	}
+
+	return bytes.Contains(apiErrorMessage(v), []byte("频繁"))
+}
+
+// 判断 body 里是否有值在 codes 里的 "result" 字段，不区分字段所在的层级
+func containsResult(body []byte, codes []int) bool {
+	if len(codes) == 0 {
+		return false
+	}
+
+	key := []byte(`"result"`)
+	for {
+		i := bytes.Index(body, key)
+		if i < 0 {
+			return false
+		}
+		body = bytes.TrimLeft(body[i+len(key):], " \t\r\n:")
+		n := 0
+		for n < len(body) && (body[n] == '-' || body[n] >= '0' && body[n] <= '9') {
+			n++
+		}
+		if code, err := strconv.Atoi(string(body[:n])); err == nil && slices.Contains(codes, code) {
+			return true
+		}
+	}
+}
+
+// 令牌桶
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitMiddleware 返回限速中间件，对每个 host 分别使用令牌桶限速，
+// rate 为每秒允许的请求数量，burst 为允许突发的最大请求数量，rate 不大于 0 时不限速
+func NewRateLimitMiddleware(rate float64, burst int) HTTPMiddleware {
+	if burst < 1 {
+		burst = 1
+	}
+	var mu sync.Mutex
+	buckets := make(map[string]*tokenBucket)
+
+	// 获取令牌，返回需要等待的时间
+	reserve := func(host string) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		b, ok := buckets[host]
+		if !ok {
+			b = &tokenBucket{tokens: float64(burst), last: now}
+			buckets[host] = b
+		}
+		b.tokens += now.Sub(b.last).Seconds() * rate
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+		b.last = now
+		// 令牌可以预支，等待到令牌足够时再请求
+		b.tokens--
+		if b.tokens >= 0 {
+			return 0
+		}
+
+		return time.Duration(-b.tokens / rate * float64(time.Second))
+	}
+
+	return func(next HTTPDoFunc) HTTPDoFunc {
+		if rate <= 0 {
+			return next
+		}
+
+		return func(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+			if delay := reserve(string(req.URI().Host())); delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+
+			return next(ctx, req, resp)
+		}
+	}
+}
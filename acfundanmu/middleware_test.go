@@ -0,0 +1,268 @@
+package acfundanmu
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestContainsResult(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		codes []int
+		want  bool
+	}{
+		{"没有 codes", `{"result":10}`, nil, false},
+		{"匹配", `{"result":10}`, []int{1, 10}, true},
+		{"有空格", `{"result" : 10}`, []int{10}, true},
+		{"负数", `{"result":-5}`, []int{-5}, true},
+		{"不匹配", `{"result":1}`, []int{10}, false},
+		{"前缀不算匹配", `{"result":100}`, []int{10}, false},
+		{"嵌套", `{"data":{"result":10}}`, []int{10}, true},
+		{"第二个匹配", `{"result":1,"data":{"result":10}}`, []int{10}, true},
+		{"字符串", `{"result":"10"}`, []int{10}, false},
+		{"没有 result", `{"code":10}`, []int{10}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsResult([]byte(tt.body), tt.codes); got != tt.want {
+				t.Errorf("containsResult(%s, %v) = %v, want %v", tt.body, tt.codes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTooFrequent(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		gzip   bool
+		codes  []int
+		want   bool
+	}{
+		{"429", fasthttp.StatusTooManyRequests, "", false, nil, true},
+		{"正常", fasthttp.StatusOK, `{"result":1}`, false, []int{10}, false},
+		{"result 匹配", fasthttp.StatusOK, `{"result":10}`, false, []int{10}, true},
+		{"嵌套的 result", fasthttp.StatusOK, `{"data":{"result":10}}`, false, []int{10}, false},
+		{"错误信息", fasthttp.StatusOK, `{"result":1,"error_msg":"请求过于频繁"}`, false, nil, true},
+		{"其他字段有频繁", fasthttp.StatusOK, `{"result":1,"data":"频繁"}`, false, nil, false},
+		{"不是 json", fasthttp.StatusOK, `频繁`, false, nil, false},
+		{"gzip", fasthttp.StatusOK, `{"result":1,"msg":"操作太频繁"}`, true, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := fasthttp.AcquireResponse()
+			defer fasthttp.ReleaseResponse(resp)
+			resp.SetStatusCode(tt.status)
+			if tt.gzip {
+				resp.Header.SetContentEncoding("gzip")
+				resp.SetBody(fasthttp.AppendGzipBytes(nil, []byte(tt.body)))
+			} else {
+				resp.SetBodyString(tt.body)
+			}
+			if got := tooFrequent(resp, tt.codes); got != tt.want {
+				t.Errorf("tooFrequent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base, maxDelay := 100*time.Millisecond, time.Second
+	tests := []struct {
+		n        int
+		min, max time.Duration
+	}{
+		{0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond, 800 * time.Millisecond},
+		{4, 500 * time.Millisecond, time.Second},
+		{100, 500 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		for range 100 {
+			if d := backoff(base, maxDelay, tt.n); d < tt.min || d > tt.max {
+				t.Fatalf("backoff(%v, %v, %d) = %v, want in [%v, %v]", base, maxDelay, tt.n, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+// 重试时的一次响应，err 不为 nil 时表示网络错误
+type fakeResponse struct {
+	status int
+	body   string
+	err    error
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	errNetwork := errors.New("network error")
+	ok := fakeResponse{status: fasthttp.StatusOK, body: `{"result":1}`}
+	tests := []struct {
+		name       string
+		config     RetryConfig
+		method     string
+		readOnly   bool
+		responses  []fakeResponse
+		wantCalls  int
+		wantErr    error
+		wantStatus int
+	}{
+		{"成功不重试", RetryConfig{MaxRetries: 3}, fasthttp.MethodGet, false,
+			[]fakeResponse{ok}, 1, nil, fasthttp.StatusOK},
+		{"GET 网络错误", RetryConfig{MaxRetries: 3}, fasthttp.MethodGet, false,
+			[]fakeResponse{{err: errNetwork}, ok}, 2, nil, fasthttp.StatusOK},
+		{"GET 5xx", RetryConfig{MaxRetries: 3}, fasthttp.MethodGet, false,
+			[]fakeResponse{{status: fasthttp.StatusBadGateway}, {status: fasthttp.StatusServiceUnavailable}, ok}, 3, nil, fasthttp.StatusOK},
+		{"超过重试次数", RetryConfig{MaxRetries: 2}, fasthttp.MethodGet, false,
+			[]fakeResponse{{err: errNetwork}, {err: errNetwork}, {err: errNetwork}, ok}, 3, errNetwork, 0},
+		{"不重试", RetryConfig{}, fasthttp.MethodGet, false,
+			[]fakeResponse{{err: errNetwork}, ok}, 1, errNetwork, 0},
+		{"4xx 不重试", RetryConfig{MaxRetries: 3}, fasthttp.MethodGet, false,
+			[]fakeResponse{{status: fasthttp.StatusNotFound}, ok}, 1, nil, fasthttp.StatusNotFound},
+		{"POST 网络错误不重试", RetryConfig{MaxRetries: 3}, fasthttp.MethodPost, false,
+			[]fakeResponse{{err: errNetwork}, ok}, 1, errNetwork, 0},
+		{"POST 5xx 不重试", RetryConfig{MaxRetries: 3}, fasthttp.MethodPost, false,
+			[]fakeResponse{{status: fasthttp.StatusBadGateway}, ok}, 1, nil, fasthttp.StatusBadGateway},
+		{"只读的 POST", RetryConfig{MaxRetries: 3}, fasthttp.MethodPost, true,
+			[]fakeResponse{{err: errNetwork}, ok}, 2, nil, fasthttp.StatusOK},
+		{"RetryNonIdempotent", RetryConfig{MaxRetries: 3, RetryNonIdempotent: true}, fasthttp.MethodPost, false,
+			[]fakeResponse{{err: errNetwork}, ok}, 2, nil, fasthttp.StatusOK},
+		{"Retryable 允许", RetryConfig{MaxRetries: 3, Retryable: func(req *fasthttp.Request) bool {
+			return string(req.URI().Path()) == "/api"
+		}}, fasthttp.MethodPost, false,
+			[]fakeResponse{{err: errNetwork}, ok}, 2, nil, fasthttp.StatusOK},
+		{"Retryable 不允许", RetryConfig{MaxRetries: 3, Retryable: func(req *fasthttp.Request) bool {
+			return false
+		}}, fasthttp.MethodPost, false,
+			[]fakeResponse{{err: errNetwork}, ok}, 1, errNetwork, 0},
+		{"POST 429 重试", RetryConfig{MaxRetries: 3}, fasthttp.MethodPost, false,
+			[]fakeResponse{{status: fasthttp.StatusTooManyRequests}, ok}, 2, nil, fasthttp.StatusOK},
+		{"POST result 过于频繁重试", RetryConfig{MaxRetries: 3, TooFrequentCodes: []int{10}}, fasthttp.MethodPost, false,
+			[]fakeResponse{{status: fasthttp.StatusOK, body: `{"result":10}`}, ok}, 2, nil, fasthttp.StatusOK},
+		{"POST 错误信息过于频繁重试", RetryConfig{MaxRetries: 3}, fasthttp.MethodPost, false,
+			[]fakeResponse{{status: fasthttp.StatusOK, body: `{"result":1,"error_msg":"请求太频繁"}`}, ok}, 2, nil, fasthttp.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.BaseDelay = time.Millisecond
+			tt.config.MaxDelay = time.Millisecond
+			tt.config.TooFrequentDelay = time.Millisecond
+			calls := 0
+			next := func(_ context.Context, _ *fasthttp.Request, resp *fasthttp.Response) error {
+				r := tt.responses[calls]
+				calls++
+				if r.err != nil {
+					return r.err
+				}
+				resp.SetStatusCode(r.status)
+				resp.SetBodyString(r.body)
+				return nil
+			}
+
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+			resp := fasthttp.AcquireResponse()
+			defer fasthttp.ReleaseResponse(resp)
+			req.SetRequestURI("https://example.com/api")
+			req.Header.SetMethod(tt.method)
+			ctx := context.Background()
+			if tt.readOnly {
+				ctx = context.WithValue(ctx, readOnlyKey{}, true)
+			}
+
+			err := NewRetryMiddleware(tt.config)(next)(ctx, req, resp)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if err == nil && resp.StatusCode() != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode(), tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRetryMiddlewareCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	next := func(context.Context, *fasthttp.Request, *fasthttp.Response) error {
+		calls++
+		cancel()
+		return errors.New("network error")
+	}
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	_ = NewRetryMiddleware(RetryConfig{MaxRetries: 3, BaseDelay: time.Hour})(next)(ctx, req, resp)
+	if calls != 1 {
+		t.Errorf("calls = %d after ctx is canceled, want 1", calls)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		burst    int
+		hosts    []string
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		{"不限速", 0, 1, []string{"a", "a", "a", "a"}, 0, 50 * time.Millisecond},
+		{"突发", 10, 4, []string{"a", "a", "a", "a"}, 0, 50 * time.Millisecond},
+		{"限速", 20, 1, []string{"a", "a", "a"}, 90 * time.Millisecond, time.Second},
+		{"不同 host 分别限速", 10, 1, []string{"a", "b", "c"}, 0, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			do := NewRateLimitMiddleware(tt.rate, tt.burst)(func(context.Context, *fasthttp.Request, *fasthttp.Response) error {
+				return nil
+			})
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+			resp := fasthttp.AcquireResponse()
+			defer fasthttp.ReleaseResponse(resp)
+
+			start := time.Now()
+			for _, host := range tt.hosts {
+				req.SetRequestURI("https://" + host + "/api")
+				if err := do(context.Background(), req, resp); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if d := time.Since(start); d < tt.minDelay || d > tt.maxDelay {
+				t.Errorf("requests took %v, want in [%v, %v]", d, tt.minDelay, tt.maxDelay)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareCanceled(t *testing.T) {
+	do := NewRateLimitMiddleware(0.001, 1)(func(context.Context, *fasthttp.Request, *fasthttp.Response) error {
+		return nil
+	})
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("https://example.com/api")
+
+	if err := do(context.Background(), req, resp); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := do(ctx, req, resp); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -2,8 +2,8 @@ package acfundanmu
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"log"
 	"mime/multipart"
 	"net/url"
 	"os"
@@ -60,22 +60,26 @@ type StopPushInfo struct {
 }
 
 // 检测开播权限
-func (t *token) checkLiveAuth() (canLive bool, e error) {
+func (t *token) checkLiveAuth(ctx context.Context) (canLive bool, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("checkLiveAuth() error: %v", err)
+			e = fmt.Errorf("checkLiveAuth() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("检测开播权限需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("检测开播权限需要登陆主播的 AcFun 帐号"))
 	}
 
 	client := &httpClient{
-		url:      checkLiveAuthURL,
-		method:   "POST",
-		cookies:  t.Cookies,
-		deviceID: t.DeviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       checkLiveAuthURL,
+		method:    "POST",
+		readOnly:  true,
+		cookies:   t.Cookies,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -85,7 +89,7 @@ func (t *token) checkLiveAuth() (canLive bool, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("检测开播权限失败，响应为 %s", string(body)))
+		panic(newAPIError("检测开播权限失败", v, body))
 	}
 
 	status := v.GetInt("authority", "status")
@@ -110,7 +114,7 @@ func getLiveType(v *fastjson.Value) *LiveType {
 		case "categoryName":
 			liveType.CategoryName = string(v.GetStringBytes())
 		default:
-			log.Printf("直播分类里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			defaultLogger().Warn("直播分类里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -118,17 +122,21 @@ func getLiveType(v *fastjson.Value) *LiveType {
 }
 
 // 获取直播分类列表
-func (t *token) getLiveTypeList() (list []LiveType, e error) {
+func (t *token) getLiveTypeList(ctx context.Context) (list []LiveType, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getLiveTypeList() error: %v", err)
+			e = fmt.Errorf("getLiveTypeList() error: %w", recoverErr(err))
 		}
 	}()
 
 	client := &httpClient{
-		url:      liveTypeListURL,
-		method:   "POST",
-		deviceID: t.DeviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       liveTypeListURL,
+		method:    "POST",
+		readOnly:  true,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -138,7 +146,7 @@ func (t *token) getLiveTypeList() (list []LiveType, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("获取直播分类失败，响应为 %s", string(body)))
+		panic(newAPIError("获取直播分类失败", v, body))
 	}
 
 	typeList := v.GetArray("typeList")
@@ -151,20 +159,20 @@ func (t *token) getLiveTypeList() (list []LiveType, e error) {
 }
 
 // 获取推流设置
-func (t *token) getPushConfig() (config *PushConfig, e error) {
+func (t *token) getPushConfig(ctx context.Context) (config *PushConfig, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getPushConfig() error: %v", err)
+			e = fmt.Errorf("getPushConfig() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取推流设置需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("获取推流设置需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
-	body, err := t.fetchKuaiShouAPI(obsConfigURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, obsConfigURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -172,7 +180,7 @@ func (t *token) getPushConfig() (config *PushConfig, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取推流设置失败，响应为 %s", string(body)))
+		panic(newAPIError("获取推流设置失败", v, body))
 	}
 
 	config = new(PushConfig)
@@ -194,7 +202,7 @@ func (t *token) getPushConfig() (config *PushConfig, e error) {
 		case "intervalMillis":
 			config.Interval = v.GetInt64()
 		default:
-			log.Printf("推流设置里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			t.logger().Warn("推流设置里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -206,20 +214,20 @@ func (t *token) getPushConfig() (config *PushConfig, e error) {
 }
 
 // 获取直播状态
-func (t *token) getLiveStatus() (status *LiveStatus, e error) {
+func (t *token) getLiveStatus(ctx context.Context) (status *LiveStatus, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getLiveStatus() error: %v", err)
+			e = fmt.Errorf("getLiveStatus() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取直播状态需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("获取直播状态需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
-	body, err := t.fetchKuaiShouAPI(obsStatusURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, obsStatusURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -227,7 +235,7 @@ func (t *token) getLiveStatus() (status *LiveStatus, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取直播状态失败，响应为 %s", string(body)))
+		panic(newAPIError("获取直播状态失败", v, body))
 	}
 
 	status = new(LiveStatus)
@@ -251,7 +259,7 @@ func (t *token) getLiveStatus() (status *LiveStatus, e error) {
 		case "bizCustomData":
 			status.BizCustomData = string(v.GetStringBytes())
 		default:
-			log.Printf("直播状态里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			t.logger().Warn("直播状态里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -259,21 +267,21 @@ func (t *token) getLiveStatus() (status *LiveStatus, e error) {
 }
 
 // 获取转码信息
-func (t *token) getTranscodeInfo(streamName string) (info []TranscodeInfo, e error) {
+func (t *token) getTranscodeInfo(ctx context.Context, streamName string) (info []TranscodeInfo, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getTranscodeInfo() error: %v", err)
+			e = fmt.Errorf("getTranscodeInfo() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("获取转码信息需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("获取转码信息需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("streamName", streamName)
-	body, err := t.fetchKuaiShouAPI(transcodeInfoURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, transcodeInfoURL, form, false, true)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -281,7 +289,7 @@ func (t *token) getTranscodeInfo(streamName string) (info []TranscodeInfo, e err
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("获取转码信息状态失败，响应为 %s", string(body)))
+		panic(newAPIError("获取转码信息状态失败", v, body))
 	}
 
 	list := v.GetArray("data", "transcodeInfoList")
@@ -305,7 +313,7 @@ func (t *token) getTranscodeInfo(streamName string) (info []TranscodeInfo, e err
 			case "template":
 				info[i].Template = string(v.GetStringBytes())
 			default:
-				log.Printf("转码信息里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+				t.logger().Warn("转码信息里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 			}
 		})
 	}
@@ -317,7 +325,7 @@ func (t *token) getTranscodeInfo(streamName string) (info []TranscodeInfo, e err
 func loadFile(file string) (data []byte, contentType string, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("loadFile() error: %v", err)
+			e = fmt.Errorf("loadFile() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -365,15 +373,15 @@ func pushQuery(title string, liveType *LiveType) (query string) {
 }
 
 // 启动直播
-func (t *token) startLive(title, coverFile, streamName string, portrait, panoramic bool, liveType *LiveType) (liveID string, e error) {
+func (t *token) startLive(ctx context.Context, title, coverFile, streamName string, portrait, panoramic bool, liveType *LiveType) (liveID string, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("startLive() error: %v", err)
+			e = fmt.Errorf("startLive() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("启动直播需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("启动直播需要登陆主播的 AcFun 帐号"))
 	}
 
 	var data []byte
@@ -387,6 +395,9 @@ func (t *token) startLive(title, coverFile, streamName string, portrait, panoram
 
 	uri := fmt.Sprintf(startPushURL, t.UserID, t.DeviceID, t.ServiceToken, streamName, portrait, panoramic) + query
 	client := &httpClient{
+		client:      t.client,
+		endpoints:   t.endpoints,
+		ctx:         ctx,
 		url:         uri,
 		body:        data,
 		method:      "POST",
@@ -402,7 +413,7 @@ func (t *token) startLive(title, coverFile, streamName string, portrait, panoram
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("启动直播失败，响应为 %s", string(body)))
+		panic(newAPIError("启动直播失败", v, body))
 	}
 
 	liveID = string(v.GetStringBytes("data", "liveId"))
@@ -411,21 +422,21 @@ func (t *token) startLive(title, coverFile, streamName string, portrait, panoram
 }
 
 // 停止直播
-func (t *token) stopLive(liveID string) (info *StopPushInfo, e error) {
+func (t *token) stopLive(ctx context.Context, liveID string) (info *StopPushInfo, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("stopLive() error: %v", err)
+			e = fmt.Errorf("stopLive() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("停止直播需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("停止直播需要登陆主播的 AcFun 帐号"))
 	}
 
 	form := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(form)
 	form.Set("liveId", liveID)
-	body, err := t.fetchKuaiShouAPI(stopPushURL, form, false)
+	body, err := t.fetchKuaiShouAPI(ctx, stopPushURL, form, false, false)
 	checkErr(err)
 
 	p := generalParserPool.Get()
@@ -433,7 +444,7 @@ func (t *token) stopLive(liveID string) (info *StopPushInfo, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("停止直播失败，响应为 %s", string(body)))
+		panic(newAPIError("停止直播失败", v, body))
 	}
 
 	info = new(StopPushInfo)
@@ -445,7 +456,7 @@ func (t *token) stopLive(liveID string) (info *StopPushInfo, e error) {
 		case "endReason":
 			info.EndReason = string(v.GetStringBytes())
 		default:
-			log.Printf("停止直播信息里出现未处理的key和value：%s %s", string(k), string(v.MarshalTo([]byte{})))
+			t.logger().Warn("停止直播信息里出现未处理的key和value", "key", string(k), "value", string(v.MarshalTo([]byte{})))
 		}
 	})
 
@@ -453,15 +464,15 @@ func (t *token) stopLive(liveID string) (info *StopPushInfo, e error) {
 }
 
 // 更改直播间标题和封面
-func (t *token) changeTitleAndCover(title, coverFile, liveID string) (e error) {
+func (t *token) changeTitleAndCover(ctx context.Context, title, coverFile, liveID string) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("changeTitleAndCover() error: %v", err)
+			e = fmt.Errorf("changeTitleAndCover() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("更改直播间标题和封面需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("更改直播间标题和封面需要登陆主播的 AcFun 帐号"))
 	}
 
 	var data []byte
@@ -475,6 +486,9 @@ func (t *token) changeTitleAndCover(title, coverFile, liveID string) (e error) {
 
 	uri := fmt.Sprintf(changeCoverURL, t.UserID, t.DeviceID, t.ServiceToken, liveID) + query
 	client := &httpClient{
+		client:      t.client,
+		endpoints:   t.endpoints,
+		ctx:         ctx,
 		url:         uri,
 		body:        data,
 		method:      "POST",
@@ -490,29 +504,33 @@ func (t *token) changeTitleAndCover(title, coverFile, liveID string) (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if v.GetInt("result") != 1 {
-		panic(fmt.Errorf("更改直播间标题和封面失败，响应为 %s", string(body)))
+		panic(newAPIError("更改直播间标题和封面失败", v, body))
 	}
 
 	return nil
 }
 
 // 查询是否允许观众剪辑直播录像
-func (t *token) getLiveCutStatus() (canCut bool, e error) {
+func (t *token) getLiveCutStatus(ctx context.Context) (canCut bool, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("getLiveCutStatus() error: %v", err)
+			e = fmt.Errorf("getLiveCutStatus() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("查询是否允许观众剪辑直播录像需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("查询是否允许观众剪辑直播录像需要登陆主播的 AcFun 帐号"))
 	}
 
 	client := &httpClient{
-		url:      liveCutStatusURL,
-		method:   "POST",
-		cookies:  t.Cookies,
-		deviceID: t.DeviceID,
+		client:    t.client,
+		endpoints: t.endpoints,
+		ctx:       ctx,
+		url:       liveCutStatusURL,
+		method:    "POST",
+		readOnly:  true,
+		cookies:   t.Cookies,
+		deviceID:  t.DeviceID,
 	}
 	body, err := client.request()
 	checkErr(err)
@@ -522,7 +540,7 @@ func (t *token) getLiveCutStatus() (canCut bool, e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("查询是否允许观众剪辑直播录像失败，响应为 %s", string(body)))
+		panic(newAPIError("查询是否允许观众剪辑直播录像失败", v, body))
 	}
 
 	status := v.GetInt("liveCutStatus")
@@ -532,19 +550,19 @@ func (t *token) getLiveCutStatus() (canCut bool, e error) {
 	if status == 2 {
 		return false, nil
 	}
-	panic(fmt.Errorf("查询是否允许观众剪辑直播录像失败，响应为 %s", string(body)))
+	panic(newAPIError("查询是否允许观众剪辑直播录像失败", v, body))
 }
 
 // 设置是否允许观众剪辑直播录像
-func (t *token) setLiveCutStatus(canCut bool) (e error) {
+func (t *token) setLiveCutStatus(ctx context.Context, canCut bool) (e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("setLiveCutStatus() error: %v", err)
+			e = fmt.Errorf("setLiveCutStatus() error: %w", recoverErr(err))
 		}
 	}()
 
 	if len(t.Cookies) == 0 {
-		panic(fmt.Errorf("设置是否允许观众剪辑直播录像需要登陆主播的 AcFun 帐号"))
+		panic(errNeedLogin("设置是否允许观众剪辑直播录像需要登陆主播的 AcFun 帐号"))
 	}
 
 	status := 1
@@ -553,6 +571,9 @@ func (t *token) setLiveCutStatus(canCut bool) (e error) {
 	}
 
 	client := &httpClient{
+		client:      t.client,
+		endpoints:   t.endpoints,
+		ctx:         ctx,
 		url:         updateLiveCutURL,
 		body:        []byte(fmt.Sprintf(liveCutStatus, status)),
 		method:      "POST",
@@ -568,12 +589,12 @@ func (t *token) setLiveCutStatus(canCut bool) (e error) {
 	v, err := p.ParseBytes(body)
 	checkErr(err)
 	if !v.Exists("result") || v.GetInt("result") != 0 {
-		panic(fmt.Errorf("设置是否允许观众剪辑直播录像失败，响应为 %s", string(body)))
+		panic(newAPIError("设置是否允许观众剪辑直播录像失败", v, body))
 	}
 
 	returnStatus := v.GetInt("liveCutStatus")
 	if status != returnStatus {
-		panic(fmt.Errorf("设置是否允许观众剪辑直播录像失败，响应为 %s", string(body)))
+		panic(newAPIError("设置是否允许观众剪辑直播录像失败", v, body))
 	}
 
 	return nil
@@ -581,52 +602,102 @@ func (t *token) setLiveCutStatus(canCut bool) (e error) {
 
 // CheckLiveAuth 检测登陆帐号是否有直播权限，需要登陆主播的 AcFun 帐号
 func (ac *AcFunLive) CheckLiveAuth() (bool, error) {
-	return ac.t.checkLiveAuth()
+	return ac.CheckLiveAuthCtx(context.Background())
+}
+
+// CheckLiveAuthCtx 和 CheckLiveAuth() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) CheckLiveAuthCtx(ctx context.Context) (bool, error) {
+	return ac.t.checkLiveAuth(ctx)
 }
 
 // GetLiveTypeList 返回直播分类列表
 func (ac *AcFunLive) GetLiveTypeList() ([]LiveType, error) {
-	return ac.t.getLiveTypeList()
+	return ac.GetLiveTypeListCtx(context.Background())
+}
+
+// GetLiveTypeListCtx 和 GetLiveTypeList() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetLiveTypeListCtx(ctx context.Context) ([]LiveType, error) {
+	return ac.t.getLiveTypeList(ctx)
 }
 
 // GetPushConfig 返回推流设置，需要登陆主播的 AcFun 帐号
 func (ac *AcFunLive) GetPushConfig() (*PushConfig, error) {
-	return ac.t.getPushConfig()
+	return ac.GetPushConfigCtx(context.Background())
+}
+
+// GetPushConfigCtx 和 GetPushConfig() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetPushConfigCtx(ctx context.Context) (*PushConfig, error) {
+	return ac.t.getPushConfig(ctx)
 }
 
 // GetLiveStatus 返回直播状态，需要登陆主播的 AcFun 帐号并启动直播后调用
 func (ac *AcFunLive) GetLiveStatus() (*LiveStatus, error) {
-	return ac.t.getLiveStatus()
+	return ac.GetLiveStatusCtx(context.Background())
+}
+
+// GetLiveStatusCtx 和 GetLiveStatus() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetLiveStatusCtx(ctx context.Context) (*LiveStatus, error) {
+	return ac.t.getLiveStatus(ctx)
 }
 
 // GetTranscodeInfo 返回转码信息，推流后调用，返回的 info 长度不为 0 说明推流成功，需要登陆主播的 AcFun 帐号
 func (ac *AcFunLive) GetTranscodeInfo(streamName string) ([]TranscodeInfo, error) {
-	return ac.t.getTranscodeInfo(streamName)
+	return ac.GetTranscodeInfoCtx(context.Background(), streamName)
+}
+
+// GetTranscodeInfoCtx 和 GetTranscodeInfo() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetTranscodeInfoCtx(ctx context.Context, streamName string) ([]TranscodeInfo, error) {
+	return ac.t.getTranscodeInfo(ctx, streamName)
 }
 
 // StartLive 启动直播，title 为直播间标题，coverFile 为直播间封面图片（可以是 gif）的本地路径或网络链接，portrait 为是否手机直播，panoramic 为是否全景直播。
 // 推流成功服务器开始转码（用 GetTranscodeInfo() 判断）后调用，title 和 coverFile 不能为空，需要登陆主播的 AcFun 帐号。
 func (ac *AcFunLive) StartLive(title, coverFile, streamName string, portrait, panoramic bool, liveType *LiveType) (liveID string, e error) {
-	return ac.t.startLive(title, coverFile, streamName, portrait, panoramic, liveType)
+	return ac.StartLiveCtx(context.Background(), title, coverFile, streamName, portrait, panoramic, liveType)
+}
+
+// StartLiveCtx 和 StartLive() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) StartLiveCtx(ctx context.Context, title, coverFile, streamName string, portrait, panoramic bool, liveType *LiveType) (liveID string, e error) {
+	return ac.t.startLive(ctx, title, coverFile, streamName, portrait, panoramic, liveType)
 }
 
 // StopLive 停止直播，需要登陆主播的 AcFun 帐号
 func (ac *AcFunLive) StopLive(liveID string) (*StopPushInfo, error) {
-	return ac.t.stopLive(liveID)
+	return ac.StopLiveCtx(context.Background(), liveID)
+}
+
+// StopLiveCtx 和 StopLive() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) StopLiveCtx(ctx context.Context, liveID string) (*StopPushInfo, error) {
+	return ac.t.stopLive(ctx, liveID)
 }
 
 // ChangeTitleAndCover 更改直播间标题和封面，coverFile 为直播间封面图片（可以是 gif）的本地路径或网络链接。
 // title 为空时会没有标题，coverFile 为空时只更改标题，需要登陆主播的 AcFun 帐号。
 func (ac *AcFunLive) ChangeTitleAndCover(title, coverFile, liveID string) error {
-	return ac.t.changeTitleAndCover(title, coverFile, liveID)
+	return ac.ChangeTitleAndCoverCtx(context.Background(), title, coverFile, liveID)
+}
+
+// ChangeTitleAndCoverCtx 和 ChangeTitleAndCover() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) ChangeTitleAndCoverCtx(ctx context.Context, title, coverFile, liveID string) error {
+	return ac.t.changeTitleAndCover(ctx, title, coverFile, liveID)
 }
 
 // GetLiveCutStatus 查询是否允许观众剪辑直播录像，需要登陆主播的 AcFun 帐号
 func (ac *AcFunLive) GetLiveCutStatus() (bool, error) {
-	return ac.t.getLiveCutStatus()
+	return ac.GetLiveCutStatusCtx(context.Background())
+}
+
+// GetLiveCutStatusCtx 和 GetLiveCutStatus() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) GetLiveCutStatusCtx(ctx context.Context) (bool, error) {
+	return ac.t.getLiveCutStatus(ctx)
 }
 
 // SetLiveCutStatus 设置是否允许观众剪辑直播录像，需要登陆主播的 AcFun 帐号，主播直播时无法设置
 func (ac *AcFunLive) SetLiveCutStatus(canCut bool) error {
-	return ac.t.setLiveCutStatus(canCut)
+	return ac.SetLiveCutStatusCtx(context.Background(), canCut)
+}
+
+// SetLiveCutStatusCtx 和 SetLiveCutStatus() 一样，ctx 可以用来取消请求
+func (ac *AcFunLive) SetLiveCutStatusCtx(ctx context.Context, canCut bool) error {
+	return ac.t.setLiveCutStatus(ctx, canCut)
 }
@@ -0,0 +1,94 @@
+package acfundanmu
+
+import (
+	"bytes"
+	"compress/gzip"
+	"sync"
+
+	"github.com/orzogc/acfundanmu/acproto"
+)
+
+// 处理弹幕数据的 goroutine 的默认数量
+const defaultWorkers = 4
+
+// 放回 pool 的缓冲区的最大容量，过大的缓冲区直接丢弃
+const maxPooledBufferSize = 64 * 1024
+
+// protobuf 消息的 pool，proto.Unmarshal() 会先重置消息，所以放回时不需要重置。
+// 放回后不能再使用消息里的指针。
+type protoPool[T any] struct {
+	pool sync.Pool
+}
+
+// 获取消息
+func (p *protoPool[T]) get() *T {
+	if m, ok := p.pool.Get().(*T); ok {
+		return m
+	}
+
+	return new(T)
+}
+
+// 放回消息
+func (p *protoPool[T]) put(m *T) {
+	p.pool.Put(m)
+}
+
+var (
+	headerPool     protoPool[acproto.PacketHeader]
+	downstreamPool protoPool[acproto.DownstreamPayload]
+	scMessagePool  protoPool[acproto.ZtLiveScMessage]
+	actionPool     protoPool[acproto.ZtLiveScActionSignal]
+	commentPool    protoPool[acproto.CommonActionSignalComment]
+	likePool       protoPool[acproto.CommonActionSignalLike]
+	enterRoomPool  protoPool[acproto.CommonActionSignalUserEnterRoom]
+	followPool     protoPool[acproto.CommonActionSignalUserFollowAuthor]
+	giftPool       protoPool[acproto.CommonActionSignalGift]
+)
+
+// 可以复用的 gzip 解压器
+type gzipDecoder struct {
+	r   bytes.Reader
+	zr  *gzip.Reader
+	buf bytes.Buffer
+}
+
+var gzipPool = sync.Pool{
+	New: func() any {
+		return new(gzipDecoder)
+	},
+}
+
+// 解压 gzip 数据，返回的数据在调用 releaseGzip() 后不能再使用
+func gunzip(data []byte) (*gzipDecoder, []byte, error) {
+	d := gzipPool.Get().(*gzipDecoder)
+	d.r.Reset(data)
+	var err error
+	if d.zr == nil {
+		d.zr, err = gzip.NewReader(&d.r)
+	} else {
+		err = d.zr.Reset(&d.r)
+	}
+	if err != nil {
+		releaseGzip(d)
+		return nil, nil, err
+	}
+
+	d.buf.Reset()
+	_, err = d.buf.ReadFrom(d.zr)
+	if err != nil {
+		releaseGzip(d)
+		return nil, nil, err
+	}
+
+	return d, d.buf.Bytes(), nil
+}
+
+// 放回 gzip 解压器
+func releaseGzip(d *gzipDecoder) {
+	d.r.Reset(nil)
+	if d.buf.Cap() > maxPooledBufferSize {
+		d.buf = bytes.Buffer{}
+	}
+	gzipPool.Put(d)
+}
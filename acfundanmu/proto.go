@@ -8,7 +8,6 @@ import (
 	"encoding/base64"
 	"encoding/binary"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
@@ -145,7 +144,6 @@ func (t *token) unregister() []byte {
 }
 
 // Ping 数据
-/*
 func (t *token) ping() []byte {
 	ping := &acproto.PingRequest{
 		PingType: acproto.PingRequest_kPostRegister,
@@ -154,12 +152,11 @@ func (t *token) ping() []byte {
 	checkErr(err)
 
 	body := t.genPayload("Basic.Ping", pingBytes)
-
 	header := t.genHeader(len(body))
+	_ = t.seqID.Inc()
 
 	return t.encode(header, body)
 }
-*/
 
 // EnterRoom 数据
 func (t *token) enterRoom() []byte {
@@ -282,11 +279,11 @@ func padding(cipherText []byte, blockSize int) []byte {
 	return append(cipherText, padText...)
 }
 
-// 将 body/payload 从数据中分离出来
+// 将 body/payload 从数据中分离出来，返回的 downstream 处理完后可以用 downstreamPool.put() 放回
 func (t *token) decode(b []byte) (downstream *acproto.DownstreamPayload, e error) {
 	defer func() {
 		if err := recover(); err != nil {
-			e = fmt.Errorf("decode() error: %v", err)
+			e = fmt.Errorf("decode() error: %w", recoverErr(err))
 		}
 	}()
 
@@ -333,10 +330,11 @@ func (t *token) decode(b []byte) (downstream *acproto.DownstreamPayload, e error
 	checkErr(err)
 
 	if reader.Len() != 0 {
-		log.Printf("decode(): reader has more %d bytes", reader.Len())
+		t.logger().Warn("decode(): reader has more bytes", "length", reader.Len())
 	}
 
-	header := &acproto.PacketHeader{}
+	header := headerPool.get()
+	defer headerPool.put(header)
 	err = proto.Unmarshal(headerBytes, header)
 	checkErr(err)
 
@@ -360,11 +358,14 @@ func (t *token) decode(b []byte) (downstream *acproto.DownstreamPayload, e error
 		panic(fmt.Errorf("decode(): the length of body/payload is wrong: payload %d header %d", len(payload), header.DecodedPayloadLen))
 	}
 
-	downstream = &acproto.DownstreamPayload{}
-	err = proto.Unmarshal(payload, downstream)
-	checkErr(err)
+	stream := downstreamPool.get()
+	err = proto.Unmarshal(payload, stream)
+	if err != nil {
+		downstreamPool.put(stream)
+		panic(err)
+	}
 
-	return downstream, nil
+	return stream, nil
 }
 
 // 解密数据，解密方式为 aes-128-cbc
@@ -373,7 +374,7 @@ func decrypt(ciphertext []byte, key []byte) []byte {
 	checkErr(err)
 
 	if len(ciphertext) < aes.BlockSize {
-		log.Println("decrypt(): the length of ciphertext is less than block size")
+		defaultLogger().Warn("decrypt(): the length of ciphertext is less than block size")
 		return nil
 	}
 
@@ -0,0 +1,238 @@
+package acfundanmu
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// GetDanmu() 的队列的默认容量
+const defaultQueueSize = 10000
+
+// 队列里的弹幕和它放入队列的顺序
+type queuedDanmu struct {
+	seq uint64
+	d   DanmuMessage
+}
+
+// 弹幕的环形缓冲区，满时扩容
+type danmuRing struct {
+	buf  []queuedDanmu
+	head int // 最旧的弹幕在 buf 里的位置
+	n    int // 弹幕的数量
+}
+
+// 非事件响应模式下的弹幕队列，关闭后仍然可以取出剩下的弹幕。
+// 低优先级弹幕和其他弹幕分开存放，丢弃最旧的弹幕或者低优先级弹幕时都只需要取出环形缓冲区的开头，取出所有弹幕时按 seq 合并
+type danmuQueue struct {
+	mu      sync.Mutex
+	high    danmuRing // 非低优先级的弹幕
+	low     danmuRing // 低优先级的弹幕
+	seq     uint64    // 下一个放入的弹幕的顺序
+	size    int       // 队列的容量，小于等于 0 时不限制
+	policy  OverflowPolicy
+	closed  bool
+	ready   chan struct{} // 有新的弹幕或者队列关闭时 close，然后替换为新的 channel
+	space   chan struct{} // 弹幕被取出或者队列关闭时 close，然后替换为新的 channel
+	done    <-chan struct{}
+	dropped *atomic.Uint64
+}
+
+// 新建弹幕队列，done 被关闭后放入弹幕不再阻塞
+func newDanmuQueue(size int, policy OverflowPolicy, done <-chan struct{}, dropped *atomic.Uint64) *danmuQueue {
+	return &danmuQueue{
+		size:    size,
+		policy:  policy,
+		ready:   make(chan struct{}),
+		space:   make(chan struct{}),
+		done:    done,
+		dropped: dropped,
+	}
+}
+
+// 通知所有等待 *ch 的 goroutine
+func broadcast(ch *chan struct{}) {
+	close(*ch)
+	*ch = make(chan struct{})
+}
+
+// 判断弹幕是否低优先级，队列满时采用 OverflowDropLowPriority 会优先丢弃
+func lowPriority(d DanmuMessage) bool {
+	switch d.(type) {
+	case *EnterRoom, *Like:
+		return true
+	default:
+		return false
+	}
+}
+
+// 放入弹幕，队列满时按 policy 处理，队列关闭后直接返回
+func (q *danmuQueue) put(d DanmuMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for !q.closed {
+		if q.size <= 0 || q.len() < q.size {
+			q.push(d)
+			broadcast(&q.ready)
+			return
+		}
+
+		switch q.policy {
+		case OverflowDropNewest:
+			_ = q.dropped.Inc()
+			return
+		case OverflowDropOldest:
+			q.popOldest()
+		case OverflowDropLowPriority:
+			if q.low.n > 0 {
+				q.low.pop()
+			} else if lowPriority(d) {
+				_ = q.dropped.Inc()
+				return
+			} else {
+				q.high.pop()
+			}
+		default:
+			space := q.space
+			q.mu.Unlock()
+			select {
+			case <-space:
+				q.mu.Lock()
+				continue
+			case <-q.done:
+				q.mu.Lock()
+				_ = q.dropped.Inc()
+				return
+			}
+		}
+		_ = q.dropped.Inc()
+	}
+}
+
+// 队列里弹幕的数量
+func (q *danmuQueue) len() int {
+	return q.high.n + q.low.n
+}
+
+// 在队列末尾放入弹幕
+func (q *danmuQueue) push(d DanmuMessage) {
+	item := queuedDanmu{seq: q.seq, d: d}
+	q.seq++
+	if lowPriority(d) {
+		q.low.push(item, q.size)
+	} else {
+		q.high.push(item, q.size)
+	}
+}
+
+// 删除队列里最旧的弹幕
+func (q *danmuQueue) popOldest() {
+	if q.low.n > 0 && (q.high.n == 0 || q.low.front().seq < q.high.front().seq) {
+		q.low.pop()
+	} else {
+		q.high.pop()
+	}
+}
+
+// 返回环形缓冲区里第 i 个弹幕在 buf 里的位置
+func (r *danmuRing) index(i int) int {
+	return (r.head + i) % len(r.buf)
+}
+
+// 在环形缓冲区末尾放入弹幕，扩容时容量不会超过 limit（小于等于 0 时不限制）
+func (r *danmuRing) push(item queuedDanmu, limit int) {
+	if r.n == len(r.buf) {
+		r.grow(limit)
+	}
+	r.buf[r.index(r.n)] = item
+	r.n++
+}
+
+// 扩大环形缓冲区
+func (r *danmuRing) grow(limit int) {
+	size := max(2*len(r.buf), 16)
+	if limit > 0 && size > limit {
+		size = limit
+	}
+	buf := make([]queuedDanmu, size)
+	end := r.head + r.n
+	if end <= len(r.buf) {
+		copy(buf, r.buf[r.head:end])
+	} else {
+		k := copy(buf, r.buf[r.head:])
+		copy(buf[k:], r.buf[:end-len(r.buf)])
+	}
+	r.buf = buf
+	r.head = 0
+}
+
+// 返回最旧的弹幕，环形缓冲区不能为空
+func (r *danmuRing) front() queuedDanmu {
+	return r.buf[r.head]
+}
+
+// 删除最旧的弹幕，环形缓冲区不能为空
+func (r *danmuRing) pop() {
+	r.buf[r.head] = queuedDanmu{}
+	r.head = (r.head + 1) % len(r.buf)
+	r.n--
+}
+
+// 清空环形缓冲区
+func (r *danmuRing) reset() {
+	clear(r.buf)
+	r.head = 0
+	r.n = 0
+}
+
+// 取出队列里所有的弹幕，队列为空时等待，队列关闭并且为空时返回 nil
+func (q *danmuQueue) get() []DanmuMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.len() == 0 {
+		if q.closed {
+			return nil
+		}
+		ready := q.ready
+		q.mu.Unlock()
+		<-ready
+		q.mu.Lock()
+	}
+
+	// 按放入的顺序合并两个环形缓冲区
+	items := make([]DanmuMessage, 0, q.len())
+	i, j := 0, 0
+	for i < q.high.n || j < q.low.n {
+		if j == q.low.n || (i < q.high.n && q.high.buf[q.high.index(i)].seq < q.low.buf[q.low.index(j)].seq) {
+			items = append(items, q.high.buf[q.high.index(i)].d)
+			i++
+		} else {
+			items = append(items, q.low.buf[q.low.index(j)].d)
+			j++
+		}
+	}
+	q.high.reset()
+	q.low.reset()
+	broadcast(&q.space)
+
+	return items
+}
+
+// 关闭队列，之后放入的弹幕会被忽略
+func (q *danmuQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		broadcast(&q.ready)
+		broadcast(&q.space)
+	}
+}
+
+// 判断队列是否已经关闭并且为空
+func (q *danmuQueue) finished() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.closed && q.len() == 0
+}
@@ -0,0 +1,151 @@
+package acfundanmu
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// 用弹幕的类型和发送时间表示队列里的弹幕，如 c1 为发送时间是 1 的评论，l2 为点赞，e3 为进入直播间
+func queueItem(s string) DanmuMessage {
+	var sendTime int64
+	_, _ = fmt.Sscanf(s[1:], "%d", &sendTime)
+	switch s[0] {
+	case 'l':
+		return &Like{SendTime: sendTime}
+	case 'e':
+		return &EnterRoom{SendTime: sendTime}
+	default:
+		return &Comment{DanmuCommon: DanmuCommon{SendTime: sendTime}}
+	}
+}
+
+func queueString(items []DanmuMessage) string {
+	s := make([]string, 0, len(items))
+	for _, d := range items {
+		var prefix string
+		switch d.(type) {
+		case *Like:
+			prefix = "l"
+		case *EnterRoom:
+			prefix = "e"
+		default:
+			prefix = "c"
+		}
+		s = append(s, fmt.Sprintf("%s%d", prefix, d.GetSendTime()))
+	}
+
+	return strings.Join(s, " ")
+}
+
+func TestDanmuQueuePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		policy  OverflowPolicy
+		put     string
+		want    string
+		dropped uint64
+	}{
+		{"不限容量", 0, OverflowDropNewest, "c1 l2 e3 c4", "c1 l2 e3 c4", 0},
+		{"未满", 4, OverflowDropNewest, "c1 l2 e3", "c1 l2 e3", 0},
+		{"丢弃最新", 2, OverflowDropNewest, "c1 l2 e3 c4", "c1 l2", 2},
+		{"丢弃最旧", 2, OverflowDropOldest, "c1 l2 e3 c4", "e3 c4", 2},
+		{"丢弃最旧的低优先级", 3, OverflowDropOldest, "l1 c2 c3 c4", "c2 c3 c4", 1},
+		{"优先丢弃低优先级", 3, OverflowDropLowPriority, "c1 l2 c3 e4 c5", "c1 c3 c5", 2},
+		{"丢弃新的低优先级", 2, OverflowDropLowPriority, "c1 c2 l3 e4", "c1 c2", 2},
+		{"没有低优先级时丢弃最旧", 2, OverflowDropLowPriority, "c1 c2 c3", "c2 c3", 1},
+		{"顺序不变", 4, OverflowDropLowPriority, "l1 c2 e3 c4 l5 c6", "c2 c4 l5 c6", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dropped := atomic.NewUint64(0)
+			q := newDanmuQueue(tt.size, tt.policy, nil, dropped)
+			for _, s := range strings.Fields(tt.put) {
+				q.put(queueItem(s))
+			}
+			if got := queueString(q.get()); got != tt.want {
+				t.Errorf("get() = %q, want %q", got, tt.want)
+			}
+			if got := dropped.Load(); got != tt.dropped {
+				t.Errorf("dropped = %d, want %d", got, tt.dropped)
+			}
+		})
+	}
+}
+
+func TestDanmuQueueGrow(t *testing.T) {
+	dropped := atomic.NewUint64(0)
+	q := newDanmuQueue(0, OverflowBlock, nil, dropped)
+	// 先放入再删除一部分，让环形缓冲区的开头不在 0
+	for i := range 10 {
+		q.put(queueItem(fmt.Sprintf("c%d", i)))
+	}
+	q.high.pop()
+	q.high.pop()
+	var want []string
+	for i := 2; i < 10; i++ {
+		want = append(want, fmt.Sprintf("c%d", i))
+	}
+	for i := 10; i < 100; i++ {
+		s := fmt.Sprintf("c%d", i)
+		if i%3 == 0 {
+			s = fmt.Sprintf("l%d", i)
+		}
+		want = append(want, s)
+		q.put(queueItem(s))
+	}
+	if got := queueString(q.get()); got != strings.Join(want, " ") {
+		t.Errorf("get() = %q, want %q", got, strings.Join(want, " "))
+	}
+	if got := dropped.Load(); got != 0 {
+		t.Errorf("dropped = %d, want 0", got)
+	}
+}
+
+func TestDanmuQueueBlock(t *testing.T) {
+	done := make(chan struct{})
+	dropped := atomic.NewUint64(0)
+	q := newDanmuQueue(1, OverflowBlock, done, dropped)
+	q.put(queueItem("c1"))
+
+	putDone := make(chan struct{})
+	go func() {
+		defer close(putDone)
+		q.put(queueItem("c2"))
+	}()
+	select {
+	case <-putDone:
+		t.Fatal("put() to full queue did not block")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if got := queueString(q.get()); got != "c1" {
+		t.Errorf("get() = %q, want %q", got, "c1")
+	}
+	<-putDone
+	if got := queueString(q.get()); got != "c2" {
+		t.Errorf("get() = %q, want %q", got, "c2")
+	}
+
+	// done 关闭后放入弹幕不再阻塞
+	q.put(queueItem("c3"))
+	close(done)
+	q.put(queueItem("c4"))
+	if got := dropped.Load(); got != 1 {
+		t.Errorf("dropped = %d, want 1", got)
+	}
+
+	q.close()
+	if q.finished() {
+		t.Error("finished() = true before the remaining danmu are taken")
+	}
+	if got := queueString(q.get()); got != "c3" {
+		t.Errorf("get() = %q, want %q", got, "c3")
+	}
+	if !q.finished() || q.get() != nil {
+		t.Error("closed empty queue is not finished")
+	}
+}
@@ -0,0 +1,81 @@
+package acfundanmu
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// ConnStats 弹幕连接的统计数据，从创建 AcFunLive 开始累计，重连后不会清零
+type ConnStats struct {
+	BytesReceived   uint64        `json:"bytesReceived"`   // 接收的字节数
+	FramesReceived  uint64        `json:"framesReceived"`  // 成功解码的弹幕数据帧数
+	DecodeErrors    uint64        `json:"decodeErrors"`    // 解码弹幕数据出现错误的次数
+	HeartbeatsSent  uint64        `json:"heartbeatsSent"`  // 发送的心跳数
+	HeartbeatsAcked uint64        `json:"heartbeatsAcked"` // 收到的心跳回应数
+	LastMessageTime time.Time     `json:"lastMessageTime"` // 最近一次收到弹幕数据的时间，没有收到过时为零值
+	PingRTT         time.Duration `json:"pingRTT"`         // 最近一次 Ping 的往返时间，没有测量过时为 0
+	Reconnects      uint64        `json:"reconnects"`      // 重连的次数
+	TicketRotations uint64        `json:"ticketRotations"` // 换用 ticket 的次数
+	EventsDropped   uint64        `json:"eventsDropped"`   // Events() 的缓冲区满时丢弃的事件数
+	DanmuDropped    uint64        `json:"danmuDropped"`    // GetDanmu() 的队列满时丢弃的弹幕数
+}
+
+// 弹幕连接的统计数据，需要用原子操作
+type connStats struct {
+	bytesReceived   atomic.Uint64
+	framesReceived  atomic.Uint64
+	decodeErrors    atomic.Uint64
+	heartbeatsSent  atomic.Uint64
+	heartbeatsAcked atomic.Uint64
+	lastMessage     atomic.Int64 // 以纳秒为单位的 Unix 时间
+	pingSent        atomic.Int64 // 最近一次发送 Ping 的时间，以纳秒为单位的 Unix 时间
+	pingRTT         atomic.Int64
+	reconnects      atomic.Uint64
+	ticketRotations atomic.Uint64
+	eventsDropped   atomic.Uint64
+	danmuDropped    atomic.Uint64
+}
+
+// 记录收到一帧弹幕数据，返回收到的时间
+func (s *connStats) frameReceived() int64 {
+	now := time.Now().UnixNano()
+	_ = s.framesReceived.Inc()
+	s.lastMessage.Store(now)
+
+	return now
+}
+
+// 记录发送了 Ping
+func (s *connStats) pingSending() {
+	s.pingSent.Store(time.Now().UnixNano())
+}
+
+// 记录收到 Ping 的回应
+func (s *connStats) pongReceived() {
+	if sent := s.pingSent.Swap(0); sent != 0 {
+		s.pingRTT.Store(time.Now().UnixNano() - sent)
+	}
+}
+
+// Stats 返回弹幕连接的统计数据
+func (ac *AcFunLive) Stats() ConnStats {
+	s := &ac.stats
+	stats := ConnStats{
+		BytesReceived:   s.bytesReceived.Load(),
+		FramesReceived:  s.framesReceived.Load(),
+		DecodeErrors:    s.decodeErrors.Load(),
+		HeartbeatsSent:  s.heartbeatsSent.Load(),
+		HeartbeatsAcked: s.heartbeatsAcked.Load(),
+		PingRTT:         time.Duration(s.pingRTT.Load()),
+		Reconnects:      s.reconnects.Load(),
+		TicketRotations: s.ticketRotations.Load(),
+		EventsDropped:   s.eventsDropped.Load(),
+		DanmuDropped:    s.danmuDropped.Load(),
+	}
+	if last := s.lastMessage.Load(); last != 0 {
+		stats.LastMessageTime = time.Unix(0, last)
+	}
+
+	return stats
+}
@@ -0,0 +1,142 @@
+package acfundanmu
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// OverflowPolicy 缓冲区满时的处理方式
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock 等待缓冲区有空位，会阻塞弹幕数据的处理（默认）
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest 丢弃新的数据
+	OverflowDropNewest
+	// OverflowDropOldest 丢弃缓冲区里最旧的数据
+	OverflowDropOldest
+	// OverflowDropLowPriority 优先丢弃缓冲区里最旧的 EnterRoom 和 Like，没有时新的数据是 EnterRoom 或 Like 则丢弃新的数据，否则丢弃最旧的数据。
+	// 只用于 GetDanmu() 的队列，Events() 按 OverflowDropOldest 处理
+	OverflowDropLowPriority
+)
+
+// Event 就是 Events() 返回的 channel 里的事件
+type Event struct {
+	Type EventType // 事件类型
+	Seq  uint64    // 事件的序号，每个 AcFunLive 从 1 开始按事件分发的顺序递增，DispatchOrdered 时和 handler 的调用顺序一致
+	// 事件数据，类型和 Type 对应的 On 开头的方法的 handler 的第二个参数一致，如 EventComment 为 *Comment、EventBananaCount 为 string，
+	// EventDanmuStop 为 error，弹幕获取正常结束时为 nil
+	Data any
+}
+
+// Events() 的事件流
+type eventStream struct {
+	mu      sync.RWMutex
+	closed  bool
+	ch      chan Event
+	done    <-chan struct{}
+	policy  OverflowPolicy
+	dropped *atomic.Uint64
+}
+
+// 发送事件，缓冲区满时按 policy 处理，ctx 结束后不再阻塞。
+// EventDanmuStop 不会被丢弃，ctx 结束后缓冲区仍然满时丢弃最旧的事件。
+func (s *eventStream) send(e Event) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- e:
+		return
+	default:
+	}
+
+	policy := s.policy
+	if e.Type == EventDanmuStop {
+		policy = OverflowBlock
+	}
+	switch policy {
+	case OverflowDropNewest:
+		_ = s.dropped.Inc()
+	case OverflowDropOldest, OverflowDropLowPriority:
+		s.replaceOldest(e)
+	default:
+		select {
+		case s.ch <- e:
+		case <-s.done:
+			if e.Type == EventDanmuStop {
+				s.replaceOldest(e)
+			} else {
+				_ = s.dropped.Inc()
+			}
+		}
+	}
+}
+
+// 丢弃缓冲区里最旧的事件直到可以放入 e
+func (s *eventStream) replaceOldest(e Event) {
+	for {
+		select {
+		case <-s.ch:
+			_ = s.dropped.Inc()
+		default:
+		}
+		select {
+		case s.ch <- e:
+			return
+		default:
+		}
+	}
+}
+
+// 关闭事件流，会等待正在发送的事件
+func (s *eventStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// Events 获取弹幕并以 channel 的方式返回所有事件，ctx 用来结束弹幕的获取，会采用事件响应模式，注册的事件 handler 也会被调用。
+// 弹幕获取结束时会先发送 EventDanmuStop 事件再关闭 channel，缓冲区的大小和缓冲区满时的处理方式用 SetEventStream() 设置。
+// 一个 AcFunLive 只能同时调用 StartDanmu() 或 Events() 一次。
+func (ac *AcFunLive) Events(ctx context.Context) <-chan Event {
+	size := ac.streamSize
+	if size <= 0 {
+		size = queueLen
+	}
+	s := &eventStream{
+		ch:      make(chan Event, size),
+		done:    ctx.Done(),
+		policy:  ac.streamPolicy,
+		dropped: &ac.stats.eventsDropped,
+	}
+
+	if ac.t.liverUID <= 0 {
+		err := fmt.Errorf("主播 uid 不能小于 1")
+		ac.t.logger().Error(err.Error())
+		s.send(Event{Type: EventDanmuStop, Data: err})
+		s.close()
+		return s.ch
+	}
+
+	ac.stream.Store(s)
+	go ac.clientStart(ctx, true, make(chan error, 1))
+
+	return s.ch
+}
+
+// 关闭 Events() 的事件流
+func (ac *AcFunLive) closeStream() {
+	if s := ac.stream.Swap(nil); s != nil {
+		s.close()
+	}
+}
@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -121,7 +122,7 @@ func (m *DanmuMonitor) refreshLiveList() {
 	log.Println("开始刷新直播列表...")
 	
 	// 获取所有直播列表
-	liveList, err := m.client.GetAllLiveList()
+	liveList, err := m.client.GetAllLiveListCtx(m.ctx)
 	if err != nil {
 		log.Printf("获取直播列表失败: %v", err)
 		return
@@ -194,6 +195,13 @@ func (m *DanmuMonitor) addListener(live acfundanmu.UserLiveInfo) error {
 	acLive, err := acfundanmu.NewAcFunLive(
 		acfundanmu.SetLiverUID(userID),
 		acfundanmu.SetTokenInfo(tokenInfo),
+		acfundanmu.SetReconnectPolicy(&acfundanmu.ReconnectPolicy{
+			MaxAttempts:     5,
+			InitialInterval: time.Second,
+			MaxInterval:     30 * time.Second,
+			Multiplier:      2,
+		}),
+		acfundanmu.SetStallWatchdog(3),
 	)
 	if err != nil {
 		return fmt.Errorf("创建AcFunLive实例失败: %w", err)
@@ -216,11 +224,11 @@ func (m *DanmuMonitor) addListener(live acfundanmu.UserLiveInfo) error {
 	
 	// 注册弹幕处理函数
 	acLive.OnComment(listener.handleComment)
+	acLive.OnReconnecting(func(ac *acfundanmu.AcFunLive, r *acfundanmu.Reconnecting) {
+		log.Printf("直播间 %s (UID: %d) 弹幕连接断开，%v 后第 %d 次重连: %v", listener.nickname, listener.userID, r.Delay, r.Attempt, r.Err)
+	})
 	acLive.OnDanmuStop(func(ac *acfundanmu.AcFunLive, err error) {
-		if err != nil {
-			log.Printf("直播间 %s (UID: %d) 弹幕监听停止: %v", listener.nickname, listener.userID, err)
-		}
-		// 下次刷新时会移除该监听器
+		m.handleListenerStop(live, listener, err)
 	})
 	
 	// 获取直播流信息并保存
@@ -296,6 +304,45 @@ func (m *DanmuMonitor) addListener(live acfundanmu.UserLiveInfo) error {
 	return nil
 }
 
+// handleListenerStop 根据弹幕监听停止的原因决定移除还是重启监听器
+func (m *DanmuMonitor) handleListenerStop(live acfundanmu.UserLiveInfo, listener *LiveListener, err error) {
+	// 监听器已经被主动停止
+	if listener.ctx.Err() != nil {
+		return
+	}
+
+	switch {
+	case errors.Is(err, acfundanmu.ErrLiveClosed):
+		log.Printf("直播间 %s (UID: %d) 已下播，移除监听器", listener.nickname, listener.userID)
+		m.removeListener(listener)
+	case errors.Is(err, acfundanmu.ErrLiveBanned):
+		log.Printf("直播间 %s (UID: %d) 被封禁，移除监听器: %v", listener.nickname, listener.userID, err)
+		m.removeListener(listener)
+	case err != nil:
+		log.Printf("直播间 %s (UID: %d) 弹幕监听停止，重启监听器: %v", listener.nickname, listener.userID, err)
+		m.removeListener(listener)
+		if err := m.addListener(live); errors.Is(err, acfundanmu.ErrNotLive) {
+			log.Printf("直播间 %s (UID: %d) 已下播，不再重启监听器", listener.nickname, listener.userID)
+		} else if err != nil {
+			log.Printf("重启监听器失败 (UID: %d): %v", listener.userID, err)
+		}
+	}
+}
+
+// removeListener 停止并移除监听器
+func (m *DanmuMonitor) removeListener(listener *LiveListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	listener.cancel()
+	if m.listeners[listener.userID] != listener {
+		return
+	}
+	delete(m.listeners, listener.userID)
+	global.GetStore().RemoveLiveRoom(listener.userID)
+	global.GetStreamManager().RemoveStream(listener.userID)
+}
+
 // 处理弹幕
 func (l *LiveListener) handleComment(_ *acfundanmu.AcFunLive, comment *acfundanmu.Comment) {
 	l.lastComment = time.Now()
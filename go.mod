@@ -9,7 +9,6 @@ require (
 
 require (
 	facette.io/natsort v0.0.0-20181210072756-2cd4dd1e2dcb // indirect
-	github.com/Workiva/go-datastructures v1.1.5 // indirect
 	github.com/andybalholm/brotli v1.1.0 // indirect
 	github.com/klauspost/compress v1.17.9 // indirect
 	github.com/orzogc/fastws v1.0.5-0.20230809182400-6c9094d8c52e // indirect